@@ -0,0 +1,343 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DeprecationWarning describe a deprecated key found while loading
+type DeprecationWarning struct {
+	Key         string
+	Replacement string
+	Message     string
+	Source      string
+}
+
+func (w DeprecationWarning) String() string {
+	msg := "config key '" + w.Key + "' is deprecated"
+	if w.Replacement != "" {
+		msg += ", use '" + w.Replacement + "'"
+	}
+	if w.Source != "" {
+		msg += " (found in " + w.Source + ")"
+	}
+	if w.Message != "" {
+		msg += ": " + w.Message
+	}
+	return msg
+}
+
+// keyMigrations hold the aliases and deprecations registered on a config
+type keyMigrations struct {
+	aliases      map[string]string // old key -> new key
+	deprecated   map[string]DeprecationWarning
+	warned       map[string]bool
+	warnedMu     sync.Mutex
+	onDeprecated func(w DeprecationWarning)
+}
+
+func newKeyMigrations() *keyMigrations {
+	return &keyMigrations{
+		aliases:    make(map[string]string),
+		deprecated: make(map[string]DeprecationWarning),
+		warned:     make(map[string]bool),
+	}
+}
+
+// rebuilt return fresh migrations for a config rebuilt from a recipe,
+// remembering the keys already reported so warnings fire once. The maps
+// are not shared, so the live config can be read while the recipe runs.
+func (m *keyMigrations) rebuilt() *keyMigrations {
+	next := newKeyMigrations()
+	if m == nil {
+		return next
+	}
+	m.warnedMu.Lock()
+	defer m.warnedMu.Unlock()
+	for key := range m.warned {
+		next.warned[key] = true
+	}
+	return next
+}
+
+// maxAliasChain stop alias cycles like a -> b -> a
+const maxAliasChain = 16
+
+// replay return a build step registering a snapshot of the aliases and
+// deprecations of m, for configs not built by a ConfigBuilder
+func (m *keyMigrations) replay() buildStep {
+	if m == nil {
+		return func(c *Config) error { return nil }
+	}
+	aliases := make(map[string]string, len(m.aliases))
+	for old, new := range m.aliases {
+		aliases[old] = new
+	}
+	deprecated := make([]DeprecationWarning, 0, len(m.deprecated))
+	for _, w := range m.deprecated {
+		deprecated = append(deprecated, w)
+	}
+	onDeprecated := m.onDeprecated
+
+	return func(c *Config) error {
+		next := c.keyMigrations()
+		for old, new := range aliases {
+			next.aliases[old] = new
+		}
+		for _, w := range deprecated {
+			next.deprecated[w.Key] = w
+		}
+		next.onDeprecated = onDeprecated
+		return nil
+	}
+}
+
+// RegisterAlias make old an alias of new: GetValue(old) reads new and
+// loaders move values set under old (or below it) to new
+func (c *Config) RegisterAlias(old, new string) {
+	c.keyMigrations().aliases[old] = new
+	c.migrate(c.Data)
+	c.notifyChanged([]string{old, new})
+}
+
+// Deprecate mark key as deprecated; when replacement is not empty key
+// becomes an alias of it. The deprecation hook fires once per deprecated
+// key found in the loaded config.
+func (c *Config) Deprecate(key, replacement, message string) {
+	m := c.keyMigrations()
+	m.deprecated[key] = DeprecationWarning{Key: key, Replacement: replacement, Message: message}
+	if replacement != "" {
+		m.aliases[key] = replacement
+	}
+	c.migrate(c.Data)
+	if replacement != "" {
+		c.notifyChanged([]string{key, replacement})
+	}
+}
+
+// OnDeprecated replace the hook called for deprecated keys (default: a
+// warning on the config logger)
+func (c *Config) OnDeprecated(fn func(w DeprecationWarning)) {
+	c.keyMigrations().onDeprecated = fn
+}
+
+// ResolveAlias return the key that key (or one of its parents) is an alias of
+func (c *Config) ResolveAlias(key string) string {
+	m := c.loadMigrations()
+	if m == nil || len(m.aliases) == 0 {
+		return key
+	}
+
+	for i := 0; i < maxAliasChain; i++ {
+		resolved, ok := m.resolveOnce(key)
+		if !ok {
+			break
+		}
+		key = resolved
+	}
+	return key
+}
+
+func (c *Config) keyMigrations() *keyMigrations {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.migrations == nil {
+		c.migrations = newKeyMigrations()
+	}
+	return c.migrations
+}
+
+// loadMigrations return the migrations, which a reload may swap
+func (c *Config) loadMigrations() *keyMigrations {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.migrations
+}
+
+// aliasesOf return the old keys that resolve to key (or one of its parents)
+func (c *Config) aliasesOf(key string) []string {
+	m := c.loadMigrations()
+	if m == nil {
+		return nil
+	}
+
+	var olds []string
+	for old := range m.aliases {
+		rest, found := strings.CutPrefix(key, c.ResolveAlias(old))
+		if found && (rest == "" || rest[0] == '.' || rest[0] == '[') {
+			olds = append(olds, old+rest)
+		}
+	}
+	sort.Strings(olds)
+	return olds
+}
+
+// resolveOnce rewrite key when it or one of its parents is an alias
+func (m *keyMigrations) resolveOnce(key string) (string, bool) {
+	if target, ok := m.aliases[key]; ok {
+		return target, true
+	}
+	for i := strings.LastIndex(key, "."); i > 0; i = strings.LastIndex(key[:i], ".") {
+		if target, ok := m.aliases[key[:i]]; ok {
+			return target + key[i:], true
+		}
+	}
+	return "", false
+}
+
+// migrate move aliased keys of data to their new keys and report the
+// deprecated keys found. Values already set under the new key in the same
+// data win over the old ones.
+func (c *Config) migrate(data map[string]interface{}) {
+	c.migrateFrom(data, c.origins)
+}
+
+// migrateFrom migrate data loaded from origins
+func (c *Config) migrateFrom(data map[string]interface{}, origins map[string]string) {
+	m := c.loadMigrations()
+	if m == nil || data == nil {
+		return
+	}
+
+	keys := make([]string, 0, len(m.deprecated)+len(m.aliases))
+	seen := make(map[string]bool)
+	for key := range m.deprecated {
+		keys = append(keys, key)
+		seen[key] = true
+	}
+	for key := range m.aliases {
+		if !seen[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		val, found := lookupKey(data, key)
+		if !found {
+			continue
+		}
+
+		c.warnDeprecated(m, key, originOf(origins, key))
+
+		target, aliased := m.aliases[key]
+		if !aliased {
+			continue
+		}
+		target = c.ResolveAlias(target)
+		deleteKey(data, key)
+		if _, exists := lookupKey(data, target); !exists {
+			setNested(data, strings.Split(target, "."), val)
+		}
+		renameOrigins(origins, key, target)
+	}
+}
+
+// migrateKey return the new name of a flat key loaded from source,
+// reporting it when deprecated
+func (c *Config) migrateKey(key, source string) string {
+	m := c.loadMigrations()
+	if m == nil {
+		return key
+	}
+	for parent := key; parent != ""; {
+		if _, deprecated := m.deprecated[parent]; deprecated {
+			c.warnDeprecated(m, parent, source)
+			break
+		}
+		i := strings.LastIndex(parent, ".")
+		if i < 0 {
+			break
+		}
+		parent = parent[:i]
+	}
+	return c.ResolveAlias(key)
+}
+
+// warnDeprecated call the deprecation hook the first time key is found
+func (c *Config) warnDeprecated(m *keyMigrations, key, source string) {
+	w, deprecated := m.deprecated[key]
+	if !deprecated {
+		return
+	}
+	m.warnedMu.Lock()
+	warned := m.warned[key]
+	m.warned[key] = true
+	m.warnedMu.Unlock()
+	if warned {
+		return
+	}
+	w.Source = source
+	if m.onDeprecated == nil {
+		c.Logger().Warn("deprecated config key",
+			"key", w.Key, "replacement", w.Replacement, "source", w.Source, "message", w.Message)
+		return
+	}
+	m.onDeprecated(w)
+}
+
+// lookupKey find key in data either nested or as a flat dotted key (env vars)
+func lookupKey(data map[string]interface{}, key string) (interface{}, bool) {
+	if val, exists := data[key]; exists {
+		return val, true
+	}
+	current := data
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		val, exists := current[part]
+		if !exists {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return val, true
+		}
+		next, ok := val.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current = next
+	}
+	return nil, false
+}
+
+// deleteKey remove key from data, pruning parent maps left empty
+func deleteKey(data map[string]interface{}, key string) {
+	if _, exists := data[key]; exists {
+		delete(data, key)
+		return
+	}
+	parts := strings.Split(key, ".")
+	parents := []map[string]interface{}{data}
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			return
+		}
+		parents = append(parents, next)
+		current = next
+	}
+
+	delete(current, parts[len(parts)-1])
+	for i := len(parents) - 1; i > 0 && len(parents[i]) == 0; i-- {
+		delete(parents[i-1], parts[i-1])
+	}
+}
+
+func originOf(origins map[string]string, key string) string {
+	return (&Config{origins: origins}).Origin(key)
+}
+
+func renameOrigins(origins map[string]string, old, new string) {
+	var moved []string
+	for key := range origins {
+		if key == old || strings.HasPrefix(key, old+".") {
+			moved = append(moved, key)
+		}
+	}
+	for _, key := range moved {
+		origins[new+strings.TrimPrefix(key, old)] = origins[key]
+		delete(origins, key)
+	}
+}
@@ -1,48 +1,212 @@
 package config
 
-import "fmt"
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+)
 
-// ConfigBuilder facilitate fluent config contruction
+// buildStep is a recorded builder layer, replayed on every Build
+type buildStep func(c *Config) error
+
+// ConfigBuilder facilitate fluent config contruction. Layers are recorded
+// as a recipe and applied in order on Build, so a ConfigWatcher can replay
+// the same recipe on reload.
 type ConfigBuilder struct {
-	config *Config
-	errors []error
+	steps      []buildStep
+	files      []string
+	sources    []Source
+	processors []Processor
+	schemas    []*Schema
+	validators []ConfigValidator
+	lastGood   string
+	logger     *slog.Logger
 }
 
 // NewConfigBuilder create a new ConfigBuilder
 func NewConfigBuilder() *ConfigBuilder {
-	return &ConfigBuilder{
-		config: NewConfig(),
-	}
+	return &ConfigBuilder{}
+}
+
+// WithAlias make old an alias of new; register it before the loaders so
+// values set under old keep their precedence
+func (b *ConfigBuilder) WithAlias(old, new string) *ConfigBuilder {
+	return b.step(func(c *Config) error {
+		c.RegisterAlias(old, new)
+		return nil
+	})
+}
+
+// WithDeprecation mark key as deprecated in favour of replacement (may be empty)
+func (b *ConfigBuilder) WithDeprecation(key, replacement, message string) *ConfigBuilder {
+	return b.step(func(c *Config) error {
+		c.Deprecate(key, replacement, message)
+		return nil
+	})
+}
+
+// OnDeprecated set the hook called once per deprecated key found
+func (b *ConfigBuilder) OnDeprecated(fn func(w DeprecationWarning)) *ConfigBuilder {
+	return b.step(func(c *Config) error {
+		c.OnDeprecated(fn)
+		return nil
+	})
 }
 
 // WithJSON add config from JSON file
 func (b *ConfigBuilder) WithJSON(filePath string) *ConfigBuilder {
-	if err := b.config.LoadFromJSON(filePath); err != nil {
-		b.errors = append(b.errors, fmt.Errorf("JSON load error: %w", err))
-	}
-	return b
+	b.files = append(b.files, filePath)
+	return b.step(func(c *Config) error {
+		if err := c.LoadFromJSON(filePath); err != nil {
+			return fmt.Errorf("JSON load error: %w", err)
+		}
+		return nil
+	})
 }
 
 // WithEnv add config from env vars
 func (b *ConfigBuilder) WithEnv(prefix string) *ConfigBuilder {
-	if err := b.config.LoadFromEnv(prefix); err != nil {
-		b.errors = append(b.errors, fmt.Errorf("ENV load error: %w", err))
-	}
+	return b.step(func(c *Config) error {
+		if err := c.LoadFromEnv(prefix); err != nil {
+			return fmt.Errorf("ENV load error: %w", err)
+		}
+		return nil
+	})
+}
+
+// WithSource add config from a Source (e.g. an HTTPSource)
+func (b *ConfigBuilder) WithSource(src Source) *ConfigBuilder {
+	b.sources = append(b.sources, src)
+	return b.step(func(c *Config) error {
+		if err := c.LoadFromSource(src); err != nil {
+			return fmt.Errorf("source load error: %w", err)
+		}
+		return nil
+	})
+}
+
+// WithOverrides add Helm-style "key=value" overrides with typed values
+func (b *ConfigBuilder) WithOverrides(values []string) *ConfigBuilder {
+	return b.withOverrides(values, overrideTyped)
+}
+
+// WithStringOverrides add "key=value" overrides keeping values as strings
+func (b *ConfigBuilder) WithStringOverrides(values []string) *ConfigBuilder {
+	return b.withOverrides(values, overrideString)
+}
+
+// WithFileOverrides add "key=path" overrides using the file content as value
+func (b *ConfigBuilder) WithFileOverrides(values []string) *ConfigBuilder {
+	return b.withOverrides(values, overrideFile)
+}
+
+func (b *ConfigBuilder) withOverrides(values []string, mode overrideMode) *ConfigBuilder {
+	return b.step(func(c *Config) error {
+		rename := func(key string) string { return c.migrateKey(key, "override") }
+		if err := applyOverrides(c.Data, values, mode, rename); err != nil {
+			return fmt.Errorf("override error: %w", err)
+		}
+		return nil
+	})
+}
+
+// WithProcessor add a processor applied to the merged config on Build
+func (b *ConfigBuilder) WithProcessor(p Processor) *ConfigBuilder {
+	b.processors = append(b.processors, p)
 	return b
 }
 
-// WithConfig add an existing config
-func (b *ConfigBuilder) WithConfig(cfg *Config) *ConfigBuilder {
-	b.config.Merge(cfg)
+// WithSecrets resolve "secret://" references on Build through the manager
+func (b *ConfigBuilder) WithSecrets(manager *SecretManager) *ConfigBuilder {
+	return b.WithProcessor(manager)
+}
+
+// WithDecryption decrypt inline "ENC[...]" values on Build with the keyring
+func (b *ConfigBuilder) WithDecryption(keyring *Keyring) *ConfigBuilder {
+	return b.WithProcessor(keyring)
+}
+
+// WithSchema apply the schema defaults and validate the config on Build
+func (b *ConfigBuilder) WithSchema(schema *Schema) *ConfigBuilder {
+	b.schemas = append(b.schemas, schema)
 	return b
 }
 
+// WithValidator validate the config on Build
+func (b *ConfigBuilder) WithValidator(v ConfigValidator) *ConfigBuilder {
+	b.validators = append(b.validators, v)
+	return b
+}
+
+// WithRules evaluate cross-key rules on Build
+func (b *ConfigBuilder) WithRules(rules *RuleSet) *ConfigBuilder {
+	return b.WithValidator(rules)
+}
+
+// WithStrict report unknown keys on Build
+func (b *ConfigBuilder) WithStrict(strict *StrictChecker) *ConfigBuilder {
+	return b.WithValidator(strict)
+}
+
+// WithJSONSchema validate the config against a JSON Schema on Build
+func (b *ConfigBuilder) WithJSONSchema(schema *JSONSchema) *ConfigBuilder {
+	return b.WithValidator(schema)
+}
+
+// WithLastGood save every successful Build (and watcher reload) to
+// filePath; when Build fails the saved config is returned instead, so a
+// service can start with its last known good config
+func (b *ConfigBuilder) WithLastGood(filePath string) *ConfigBuilder {
+	b.lastGood = filePath
+	return b
+}
+
+// WithLogger set the logger of the built config, also used by watchers
+func (b *ConfigBuilder) WithLogger(logger *slog.Logger) *ConfigBuilder {
+	b.logger = logger
+	return b
+}
+
+// WithConfig add an existing config; its data is copied on every Build
+func (b *ConfigBuilder) WithConfig(cfg *Config) *ConfigBuilder {
+	return b.step(func(c *Config) error {
+		if cfg != nil {
+			c.Merge(&Config{Data: cloneMap(cfg.data()), origins: cfg.origins})
+		}
+		return nil
+	})
+}
+
 // Build final config
 func (b *ConfigBuilder) Build() (*Config, error) {
-	if len(b.errors) > 0 {
-		return nil, MultiError{Errors: b.errors}
+	cfg, err := b.build(nil)
+	if err != nil {
+		if b.lastGood == "" {
+			return nil, err
+		}
+		fallback, fallbackErr := LoadLastGood(b.lastGood)
+		if fallbackErr == nil {
+			// Saved before processors ran: resolve secrets and decrypt again
+			fallback.log = b.logger
+			if errs := b.finish(fallback); len(errs) > 0 {
+				fallbackErr = fmt.Errorf("error processing last good config: %w", MultiError{Errors: errs})
+			}
+		}
+		if fallbackErr != nil {
+			return nil, MultiError{Errors: []error{err, fallbackErr}}
+		}
+		fallback.Logger().Warn("config build failed, using last good config", "path", b.lastGood, "error", err)
+		cfg = fallback
+	} else if b.lastGood != "" {
+		if err := SaveLastGood(cfg, b.lastGood); err != nil {
+			cfg.Logger().Error("error saving last good config", "path", b.lastGood, "error", err)
+		}
 	}
-	return b.config, nil
+
+	// Watchers replay the recipe, so a fallback config recovers on reload.
+	// A snapshot, so layers chained on b afterwards do not leak in.
+	cfg.recipe = b.clone()
+	return cfg, nil
 }
 
 // MustBuild build config or get in panic
@@ -53,3 +217,118 @@ func (b *ConfigBuilder) MustBuild() *Config {
 	}
 	return cfg
 }
+
+// build run the recipe on a fresh config; the reported deprecations and
+// sensitive patterns are carried over from previous when not nil
+func (b *ConfigBuilder) build(previous *Config) (*Config, error) {
+	cfg := NewConfig()
+	cfg.log = b.logger
+	if previous != nil {
+		cfg.migrations = previous.loadMigrations().rebuilt()
+		cfg.sensitive = previous.sensitive
+		cfg.log = previous.log
+	}
+
+	var errs []error
+	for _, step := range b.steps {
+		if err := step(cfg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	errs = append(errs, b.finish(cfg)...)
+
+	if len(errs) > 0 {
+		return nil, MultiError{Errors: errs}
+	}
+	return cfg, nil
+}
+
+// finish run processors, schemas and validators on a loaded config. The
+// tree as it was before processing is kept for SaveLastGood, so resolved
+// secrets and decrypted values are never written to disk.
+func (b *ConfigBuilder) finish(cfg *Config) []error {
+	if len(b.processors) > 0 {
+		cfg.raw = cloneMap(cfg.Data)
+	}
+
+	var errs []error
+	for _, p := range b.processors {
+		if err := process(p, cfg); err != nil {
+			errs = append(errs, fmt.Errorf("processing error: %w", err))
+		}
+	}
+
+	for _, schema := range b.schemas {
+		schema.ApplyDefaults(cfg)
+		if err := cfg.ValidateSchema(schema); err != nil {
+			errs = append(errs, fmt.Errorf("schema validation error: %w", err))
+		}
+	}
+
+	for _, v := range b.validators {
+		if err := v.ValidateConfig(cfg); err != nil {
+			errs = append(errs, fmt.Errorf("validation error: %w", err))
+		}
+	}
+	return errs
+}
+
+func (b *ConfigBuilder) step(step buildStep) *ConfigBuilder {
+	b.steps = append(b.steps, step)
+	return b
+}
+
+// clone copy the recipe so layers added to the copy do not affect b
+func (b *ConfigBuilder) clone() *ConfigBuilder {
+	return &ConfigBuilder{
+		steps:      append([]buildStep(nil), b.steps...),
+		files:      append([]string(nil), b.files...),
+		sources:    append([]Source(nil), b.sources...),
+		processors: append([]Processor(nil), b.processors...),
+		schemas:    append([]*Schema(nil), b.schemas...),
+		validators: append([]ConfigValidator(nil), b.validators...),
+		lastGood:   b.lastGood,
+		logger:     b.logger,
+	}
+}
+
+// hasFile report if the recipe already loads filePath
+func (b *ConfigBuilder) hasFile(filePath string) bool {
+	for _, file := range b.files {
+		if file == filePath {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSource report if the recipe already loads src
+func (b *ConfigBuilder) hasSource(src Source) bool {
+	for _, existing := range b.sources {
+		if sameValue(existing, src) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasProcessor report if the recipe already applies p
+func (b *ConfigBuilder) hasProcessor(p Processor) bool {
+	for _, existing := range b.processors {
+		if sameValue(existing, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameValue compare interface values without panicking on func types
+func sameValue(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if reflect.TypeOf(a) != reflect.TypeOf(b) || !reflect.TypeOf(a).Comparable() {
+		return false
+	}
+	return a == b
+}
@@ -1,6 +1,10 @@
 package config
 
-import "sync"
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
 
 // cachedValue represents a value in cache
 type cachedValue struct {
@@ -8,93 +12,175 @@ type cachedValue struct {
 	valid bool
 }
 
+// cacheKey identify a cached value by config key and requested type, so
+// GetInt and GetString on the same key never share an entry
+type cacheKey struct {
+	key string
+	typ reflect.Type
+}
+
 // ConfigWithCache extends Config with capabilities of cache
 type ConfigWithCache struct {
 	*Config
-	cache map[string]cachedValue
+	cache map[cacheKey]cachedValue
 	mu    sync.RWMutex
+	close func()
+	// generation is bumped by every invalidation; a value loaded before
+	// an invalidation may be stale and is not stored
+	generation uint64
 }
 
-// NewConfigWithCache create a config with cache
+var _ Configurer = (*ConfigWithCache)(nil)
+
+// NewConfigWithCache create a config with cache. Reloads swapped in by a
+// ConfigWatcher and direct Merge, Set or SetData calls on base invalidate
+// the changed keys and their parent and child paths.
 func NewConfigWithCache(base *Config) *ConfigWithCache {
-	return &ConfigWithCache{
+	c := &ConfigWithCache{
 		Config: base,
-		cache:  make(map[string]cachedValue),
+		cache:  make(map[cacheKey]cachedValue),
 	}
+	c.close = base.onKeysChanged("ConfigWithCache", c.invalidateKeys)
+	return c
+}
+
+// Close detach the cache from base; later changes are no longer invalidated
+func (c *ConfigWithCache) Close() {
+	c.close()
 }
 
 // InvalidateCache clean cache
 func (c *ConfigWithCache) InvalidateCache() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.cache = make(map[string]cachedValue)
+	c.cache = make(map[cacheKey]cachedValue)
+	c.generation++
 }
 
-// InvalidateKey clean an specific key from cache
+// InvalidateKey clean an specific key from cache, for every type
 func (c *ConfigWithCache) InvalidateKey(key string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	delete(c.cache, key)
+	c.generation++
+	for cached := range c.cache {
+		if cached.key == key {
+			delete(c.cache, cached)
+		}
+	}
 }
 
 // GetString with cache
 func (c *ConfigWithCache) GetString(key string) (string, error) {
-	if val, ok := c.getFromCache(key); ok {
-		return val.(string), nil
+	return cachedGet(c, key, c.Config.GetString)
+}
+
+// GetInt with cache
+func (c *ConfigWithCache) GetInt(key string) (int, error) {
+	return cachedGet(c, key, c.Config.GetInt)
+}
+
+// GetBool with cache
+func (c *ConfigWithCache) GetBool(key string) (bool, error) {
+	return cachedGet(c, key, c.Config.GetBool)
+}
+
+// GetValue with cache
+func (c *ConfigWithCache) GetValue(key string) (interface{}, error) {
+	return cachedGet(c, key, c.Config.GetValue)
+}
+
+// Get read key as T, decoded like UnmarshalKey (numbers, durations, slices,
+// maps and structs). A ConfigWithCache caches the result per key and type.
+func Get[T any](g Getter, key string) (T, error) {
+	if cached, ok := g.(*ConfigWithCache); ok {
+		return cachedGet(cached, key, func(key string) (T, error) {
+			return getAs[T](cached.Config, key)
+		})
 	}
+	return getAs[T](g, key)
+}
 
-	val, err := c.Config.GetString(key)
+func getAs[T any](g Getter, key string) (T, error) {
+	var out T
+	raw, err := g.GetValue(key)
 	if err != nil {
-		return "", err
+		return out, err
 	}
 
-	c.setCache(key, val)
-	return val, nil
+	var errs []error
+	decodeValue(raw, reflect.ValueOf(&out).Elem(), key, &errs)
+	if len(errs) > 0 {
+		return out, MultiError{Errors: errs}
+	}
+	return out, nil
 }
 
-// GetInt with cache
-func (c *ConfigWithCache) GetInt(key string) (int, error) {
-	if val, ok := c.getFromCache(key); ok {
-		return val.(int), nil
+// cachedGet return the cached T for key, loading and caching it on a miss.
+// Errors are not cached.
+func cachedGet[T any](c *ConfigWithCache, key string, load func(key string) (T, error)) (T, error) {
+	id := cacheKey{key: key, typ: reflect.TypeFor[T]()}
+	cached, generation, ok := c.getFromCache(id)
+	if ok {
+		if typed, ok := cached.(T); ok {
+			return typed, nil
+		}
 	}
 
-	val, err := c.Config.GetInt(key)
+	val, err := load(key)
 	if err != nil {
-		return 0, err
+		return val, err
 	}
 
-	c.setCache(key, val)
+	c.setCache(id, val, generation)
 	return val, nil
 }
 
-// GetBool with cache
-func (c *ConfigWithCache) GetBool(key string) (bool, error) {
-	if val, ok := c.getFromCache(key); ok {
-		return val.(bool), nil
+// invalidateKeys drop the cached keys related to the changed keys; nil
+// keys clean the whole cache
+func (c *ConfigWithCache) invalidateKeys(keys []string) error {
+	if keys == nil {
+		c.InvalidateCache()
+		return nil
 	}
 
-	val, err := c.Config.GetBool(key)
-	if err != nil {
-		return false, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for cached := range c.cache {
+		resolved := c.Config.ResolveAlias(cached.key)
+		for _, changed := range keys {
+			if relatedKeys(cached.key, changed) || relatedKeys(resolved, changed) {
+				delete(c.cache, cached)
+				break
+			}
+		}
 	}
+	return nil
+}
 
-	c.setCache(key, val)
-	return val, nil
+// relatedKeys report if a and b are the same key or one is under the other
+func relatedKeys(a, b string) bool {
+	return a == b || strings.HasPrefix(a, b+".") || strings.HasPrefix(b, a+".")
 }
 
-func (c *ConfigWithCache) getFromCache(key string) (interface{}, bool) {
+// getFromCache return the cached value and the current generation
+func (c *ConfigWithCache) getFromCache(key cacheKey) (interface{}, uint64, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	cached, exists := c.cache[key]
 	if exists && cached.valid {
-		return cached.value, true
+		return cached.value, c.generation, true
 	}
-	return nil, false
+	return nil, c.generation, false
 }
 
-func (c *ConfigWithCache) setCache(key string, value interface{}) {
+// setCache store value unless the cache was invalidated since generation
+func (c *ConfigWithCache) setCache(key cacheKey, value interface{}, generation uint64) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.generation != generation {
+		return
+	}
 	c.cache[key] = cachedValue{value: value, valid: true}
 }
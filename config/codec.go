@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strings"
+	"sync"
+)
+
+// Codec decode raw content into a config tree
+type Codec interface {
+	Decode(data []byte) (map[string]interface{}, error)
+}
+
+// CodecFunc adapt a function to the Codec interface
+type CodecFunc func(data []byte) (map[string]interface{}, error)
+
+// Decode call the function
+func (f CodecFunc) Decode(data []byte) (map[string]interface{}, error) {
+	return f(data)
+}
+
+// JSONCodec decode JSON content
+type JSONCodec struct{}
+
+// Decode JSON content into a config tree
+func (JSONCodec) Decode(data []byte) (map[string]interface{}, error) {
+	var tempData map[string]interface{}
+	if err := json.Unmarshal(data, &tempData); err != nil {
+		return nil, fmt.Errorf("error decoding JSON: %w", err)
+	}
+	return tempData, nil
+}
+
+var (
+	codecsMu sync.RWMutex
+	codecs   = map[string]Codec{
+		"application/json": JSONCodec{},
+		"text/json":        JSONCodec{},
+	}
+)
+
+// RegisterCodec register a codec for a media type (e.g. "application/yaml")
+func RegisterCodec(mediaType string, codec Codec) {
+	codecsMu.Lock()
+	defer codecsMu.Unlock()
+	codecs[strings.ToLower(mediaType)] = codec
+}
+
+// CodecFor return the codec registered for a Content-Type value.
+// Media types with a "+json" suffix fall back to JSON.
+func CodecFor(contentType string) (Codec, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(contentType))
+	}
+
+	codecsMu.RLock()
+	defer codecsMu.RUnlock()
+	if codec, ok := codecs[mediaType]; ok {
+		return codec, true
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return JSONCodec{}, true
+	}
+	return nil, false
+}
@@ -1,6 +1,11 @@
 package config
 
-import "time"
+import (
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
 
 // Configurer define operations config interface
 type Configurer interface {
@@ -14,10 +19,19 @@ type Loader interface {
 	Merge(other *Config)
 }
 
-// Config implements interfaces
+// Config implements interfaces. Reads through the getters are safe while
+// a ConfigWatcher swaps in a reloaded config.
 type Config struct {
 	Data         map[string]interface{}
 	LastModified time.Time
+	raw          map[string]interface{} // Data before processors ran, if any
+	sensitive    []string
+	origins      map[string]string
+	migrations   *keyMigrations
+	recipe       *ConfigBuilder
+	log          *slog.Logger
+	listeners    []*keyListener
+	mu           sync.RWMutex
 }
 
 // NewConfig create a new instance
@@ -26,5 +40,110 @@ func NewConfig() *Config {
 }
 
 func (c *Config) SetData(data map[string]interface{}) {
+	c.migrateFrom(data, nil)
+
+	c.mu.Lock()
 	c.Data = data
+	c.raw = nil
+	c.origins = nil
+	c.mu.Unlock()
+	c.notifyChanged(nil)
+}
+
+// Set value at a dot-notation key, creating intermediate maps
+func (c *Config) Set(key string, value interface{}) {
+	c.mu.Lock()
+	if c.Data == nil {
+		c.Data = make(map[string]interface{})
+	}
+	setNested(c.Data, strings.Split(key, "."), value)
+	if c.raw != nil {
+		setNested(c.raw, strings.Split(key, "."), value)
+	}
+	c.mu.Unlock()
+	c.notifyChanged([]string{key})
+}
+
+// SetLogger set the logger used for config warnings (default: slog.Default)
+func (c *Config) SetLogger(logger *slog.Logger) {
+	c.log = logger
+}
+
+// Logger return the config logger
+func (c *Config) Logger() *slog.Logger {
+	if c.log != nil {
+		return c.log
+	}
+	return slog.Default()
+}
+
+// data return the current config tree
+func (c *Config) data() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.Data
+}
+
+// replace swap in the data of a rebuilt config, returning the changes.
+// Listeners are not notified: the watcher dispatches them.
+func (c *Config) replace(other *Config) []Change {
+	c.mu.Lock()
+	changes := diffMaps(c, c.Data, other.Data)
+	c.Data = other.Data
+	c.raw = other.raw
+	c.origins = other.origins
+	c.migrations = other.migrations
+	c.LastModified = time.Now()
+	c.mu.Unlock()
+	return changes
+}
+
+// keyListener is an onKeysChanged subscription
+type keyListener struct {
+	name string
+	fn   func(keys []string) error
+}
+
+// onKeysChanged register a listener called after keys were changed in
+// place or swapped in; nil keys means the whole config changed. The
+// returned func unregisters it.
+func (c *Config) onKeysChanged(name string, listener func(keys []string) error) func() {
+	l := &keyListener{name: name, fn: listener}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, l)
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		listeners := make([]*keyListener, 0, len(c.listeners))
+		for _, other := range c.listeners {
+			if other != l {
+				listeners = append(listeners, other)
+			}
+		}
+		c.listeners = listeners
+	}
+}
+
+// listenerCalls return the listeners bound to keys, ready to dispatch
+func (c *Config) listenerCalls(keys []string) []namedCallback {
+	c.mu.RLock()
+	listeners := c.listeners
+	c.mu.RUnlock()
+
+	calls := make([]namedCallback, len(listeners))
+	for i, l := range listeners {
+		calls[i] = namedCallback{name: l.name, fn: func() error { return l.fn(keys) }}
+	}
+	return calls
+}
+
+// notifyChanged run the listeners, logging their errors and panics
+func (c *Config) notifyChanged(keys []string) {
+	for _, call := range c.listenerCalls(keys) {
+		if err := safeCall(call.name, call.fn); err != nil {
+			c.Logger().Error("config listener error", "error", err)
+		}
+	}
 }
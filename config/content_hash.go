@@ -0,0 +1,102 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// contentHash return the SHA-256 of a file, or of the visible files of a
+// directory (names and contents, following symlinks); "" when missing
+func contentHash(path string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.New()
+	if !info.IsDir() {
+		if err := hashFile(hash, path); err != nil {
+			return ""
+		}
+		return hex.EncodeToString(hash.Sum(nil))
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return ""
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue // Same files as DirSource
+		}
+		filePath := filepath.Join(path, name)
+		if info, err := os.Stat(filePath); err != nil || info.IsDir() {
+			continue
+		}
+		io.WriteString(hash, name+"\x00")
+		hashFile(hash, filePath)
+		io.WriteString(hash, "\x00")
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func hashFile(w io.Writer, filePath string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = io.Copy(w, file)
+	return err
+}
+
+// currentHashes hash every watched file and source directory
+func (w *ConfigWatcher) currentHashes() map[string]string {
+	hashes := make(map[string]string, len(w.filePaths)+len(w.dirs))
+	for _, path := range w.filePaths {
+		hashes[path] = contentHash(path)
+	}
+	for _, path := range w.dirs {
+		hashes[path] = contentHash(path)
+	}
+	return hashes
+}
+
+// contentChanged report if a watched file or source directory content
+// differs from the last reload attempt
+func (w *ConfigWatcher) contentChanged() bool {
+	current := w.currentHashes()
+
+	w.hashMu.Lock()
+	defer w.hashMu.Unlock()
+	for path, hash := range current {
+		if w.hashes[path] != hash {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *ConfigWatcher) storeHashes(hashes map[string]string) {
+	w.hashMu.Lock()
+	defer w.hashMu.Unlock()
+	w.hashes = hashes
+}
+
+// storeHash record the hash of a path watched after the last reload, so
+// registering it is not seen as a change
+func (w *ConfigWatcher) storeHash(path, hash string) {
+	w.hashMu.Lock()
+	defer w.hashMu.Unlock()
+	if w.hashes == nil {
+		w.hashes = make(map[string]string)
+	}
+	w.hashes[path] = hash
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ChangeType is the kind of change of a single key
+type ChangeType string
+
+const (
+	ChangeAdded    ChangeType = "added"
+	ChangeRemoved  ChangeType = "removed"
+	ChangeModified ChangeType = "changed"
+)
+
+// Change describe a leaf key that differs between two configs.
+// OldValue is nil for added keys and NewValue is nil for removed keys.
+type Change struct {
+	Key      string
+	Type     ChangeType
+	OldValue interface{}
+	NewValue interface{}
+	config   *Config // Sensitive patterns used by String
+}
+
+// ChangeEvent hold the changes applied by a reload, sorted by key
+type ChangeEvent struct {
+	Changes []Change
+	Config  *Config
+}
+
+// Keys return the changed keys
+func (e ChangeEvent) Keys() []string {
+	keys := make([]string, len(e.Changes))
+	for i, change := range e.Changes {
+		keys[i] = change.Key
+	}
+	return keys
+}
+
+// Has report if keyOrPrefix or anything under it changed
+func (e ChangeEvent) Has(keyOrPrefix string) bool {
+	for _, change := range e.Changes {
+		if keyMatches(change.Key, keyOrPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Get return the change of key, if any
+func (e ChangeEvent) Get(key string) (Change, bool) {
+	for _, change := range e.Changes {
+		if change.Key == key {
+			return change, true
+		}
+	}
+	return Change{}, false
+}
+
+// Filter return the event restricted to keyOrPrefix
+func (e ChangeEvent) Filter(keyOrPrefix string) ChangeEvent {
+	filtered := ChangeEvent{Config: e.Config}
+	for _, change := range e.Changes {
+		if keyMatches(change.Key, keyOrPrefix) {
+			filtered.Changes = append(filtered.Changes, change)
+		}
+	}
+	return filtered
+}
+
+// Redacted return the changes with sensitive values masked
+func (e ChangeEvent) Redacted() []Change {
+	redacted := make([]Change, len(e.Changes))
+	for i, change := range e.Changes {
+		redacted[i] = change
+		if e.Config != nil {
+			redacted[i].OldValue = maskChangeValue(e.Config, change.Key, change.OldValue)
+			redacted[i].NewValue = maskChangeValue(e.Config, change.Key, change.NewValue)
+		}
+	}
+	return redacted
+}
+
+// String describe the changes, one per line, with sensitive values masked
+func (e ChangeEvent) String() string {
+	lines := make([]string, len(e.Changes))
+	for i, change := range e.Redacted() {
+		lines[i] = change.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// String describe the change with sensitive values masked, using the
+// patterns of the config that produced it (or the default patterns)
+func (c Change) String() string {
+	redactor := c.config
+	if redactor == nil {
+		redactor = NewConfig()
+	}
+	oldValue := maskChangeValue(redactor, c.Key, c.OldValue)
+	newValue := maskChangeValue(redactor, c.Key, c.NewValue)
+
+	switch c.Type {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s = %v", c.Key, newValue)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s (was %v)", c.Key, oldValue)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", c.Key, oldValue, newValue)
+	}
+}
+
+// Diff return the leaf keys that differ from c to other, sorted by key.
+// Lists are compared as a whole. The values are raw, but String masks the
+// keys sensitive in c.
+func (c *Config) Diff(other *Config) []Change {
+	return diffMaps(c, c.data(), other.data())
+}
+
+// diffMaps compare two trees; owner provides the sensitive patterns
+func diffMaps(owner *Config, old, new map[string]interface{}) []Change {
+	oldLeaves := make(map[string]interface{})
+	newLeaves := make(map[string]interface{})
+	flattenLeaves(old, "", oldLeaves)
+	flattenLeaves(new, "", newLeaves)
+
+	var changes []Change
+	for key, oldVal := range oldLeaves {
+		newVal, exists := newLeaves[key]
+		switch {
+		case !exists:
+			changes = append(changes, Change{Key: key, Type: ChangeRemoved, OldValue: oldVal})
+		case !reflect.DeepEqual(oldVal, newVal):
+			changes = append(changes, Change{Key: key, Type: ChangeModified, OldValue: oldVal, NewValue: newVal})
+		}
+	}
+	for key, newVal := range newLeaves {
+		if _, exists := oldLeaves[key]; !exists {
+			changes = append(changes, Change{Key: key, Type: ChangeAdded, NewValue: newVal})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Key < changes[j].Key })
+	for i := range changes {
+		changes[i].config = owner
+	}
+	return changes
+}
+
+// flattenLeaves collect the non-map values (and empty maps) of data by dot-notation key
+func flattenLeaves(data map[string]interface{}, prefix string, leaves map[string]interface{}) {
+	for name, val := range data {
+		key := joinKey(prefix, name)
+		if nested, ok := val.(map[string]interface{}); ok && len(nested) > 0 {
+			flattenLeaves(nested, key, leaves)
+			continue
+		}
+		leaves[key] = val
+	}
+}
+
+// keyMatches report if key is keyOrPrefix, is under it, or is a parent of it
+// (e.g. "db" replaced by a scalar affects a watch on "db.host")
+func keyMatches(key, keyOrPrefix string) bool {
+	return keyOrPrefix == "" ||
+		key == keyOrPrefix ||
+		strings.HasPrefix(key, keyOrPrefix+".") ||
+		strings.HasPrefix(keyOrPrefix, key+".")
+}
+
+func maskChangeValue(c *Config, key string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	return c.RedactValue(key, value)
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// kubernetesDataDir is the symlink Kubernetes swaps atomically when a
+// mounted ConfigMap or Secret is updated
+const kubernetesDataDir = "..data"
+
+// WatchedSource is a Source backed by local paths the watcher should observe
+type WatchedSource interface {
+	Source
+	WatchPaths() []string
+}
+
+// DirSource load config from a directory where each file name is a key and
+// its content the value, like a mounted Kubernetes ConfigMap or Secret
+type DirSource struct {
+	dir        string
+	separator  string
+	decodeJSON bool
+}
+
+// NewDirSource create a directory-as-keys source
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{dir: dir}
+}
+
+// WithSeparator split file names by separator into nested keys
+// (e.g. "__" maps "db__host" to "db.host")
+func (s *DirSource) WithSeparator(separator string) *DirSource {
+	s.separator = separator
+	return s
+}
+
+// WithJSONValues decode file contents that are valid JSON instead of keeping them as strings
+func (s *DirSource) WithJSONValues() *DirSource {
+	s.decodeJSON = true
+	return s
+}
+
+// Load read every visible file of the directory
+func (s *DirSource) Load() (map[string]interface{}, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config directory: %w", err)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	data := make(map[string]interface{})
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue // Hidden files and Kubernetes "..data" internals
+		}
+
+		path := filepath.Join(s.dir, name)
+		info, err := os.Stat(path) // Follow the symlinks into "..data"
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", name, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading config file %s: %w", name, err)
+		}
+
+		parts := []string{name}
+		if s.separator != "" {
+			parts = nil
+			for _, part := range strings.Split(name, s.separator) {
+				if part != "" {
+					parts = append(parts, part)
+				}
+			}
+			if len(parts) == 0 {
+				continue
+			}
+		}
+		setNested(data, parts, s.decodeValue(content))
+	}
+	return data, nil
+}
+
+// WatchPaths return the directory to observe
+func (s *DirSource) WatchPaths() []string {
+	return []string{s.dir}
+}
+
+func (s *DirSource) decodeValue(content []byte) interface{} {
+	if s.decodeJSON {
+		var decoded interface{}
+		if err := json.Unmarshal(content, &decoded); err == nil {
+			return decoded
+		}
+	}
+	return string(content)
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DispatchMode control how reload callbacks are run
+type DispatchMode int
+
+const (
+	// DispatchOrdered run callbacks one after another in registration order
+	DispatchOrdered DispatchMode = iota
+	// DispatchParallel run callbacks concurrently and wait for all of them
+	DispatchParallel
+)
+
+// DefaultCallbackTimeout is how long a reload waits for each callback
+const DefaultCallbackTimeout = 30 * time.Second
+
+// SetDispatchMode choose ordered (default) or parallel callback dispatch
+func (w *ConfigWatcher) SetDispatchMode(mode DispatchMode) {
+	w.dispatchMode = mode
+}
+
+// SetCallbackTimeout bound how long a reload waits for a callback; a
+// callback over the limit is reported and left running in the background.
+// 0 waits forever.
+func (w *ConfigWatcher) SetCallbackTimeout(timeout time.Duration) {
+	w.callbackTimeout = timeout
+}
+
+// namedCallback is a callback ready to dispatch, named for error reports
+type namedCallback struct {
+	name string
+	fn   func() error
+}
+
+// dispatch run callbacks isolated from each other and from the watcher:
+// panics and timeouts are reported on the Errors channel
+func (w *ConfigWatcher) dispatch(calls []namedCallback) {
+	if w.dispatchMode != DispatchParallel {
+		for _, call := range calls {
+			w.invoke(call)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for _, call := range calls {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.invoke(call)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *ConfigWatcher) invoke(call namedCallback) {
+	if w.callbackTimeout <= 0 {
+		if err := safeCall(call.name, call.fn); err != nil {
+			w.report(err)
+		}
+		return
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- safeCall(call.name, call.fn) }()
+
+	timer := time.NewTimer(w.callbackTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			w.report(err)
+		}
+	case <-timer.C:
+		w.report(&CallbackError{Callback: call.name, Timeout: w.callbackTimeout})
+		go func() {
+			if err := <-done; err != nil {
+				w.report(err) // Late panic
+			}
+		}()
+	}
+}
+
+// safeCall run fn turning a panic into a CallbackError
+func safeCall(name string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &CallbackError{Callback: name, Panic: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn()
+}
+
+// reloadCallbacks list the OnReload callbacks and the Watch subscriptions
+// concerned by changes
+func (w *ConfigWatcher) reloadCallbacks(changes []Change) []namedCallback {
+	calls := make([]namedCallback, 0, len(w.callbacks)+len(w.watches))
+	for i, callback := range w.callbacks {
+		calls = append(calls, namedCallback{
+			name: fmt.Sprintf("OnReload #%d", i+1),
+			fn:   func() error { callback(w.config); return nil },
+		})
+	}
+
+	if len(changes) == 0 {
+		return calls
+	}
+	event := ChangeEvent{Changes: changes, Config: w.config}
+	for i, watch := range w.watches {
+		filtered := event.Filter(watch.prefix)
+		if len(filtered.Changes) == 0 {
+			continue
+		}
+		calls = append(calls, namedCallback{
+			name: fmt.Sprintf("Watch #%d '%s'", i+1, watch.prefix),
+			fn:   func() error { watch.callback(filtered); return nil },
+		})
+	}
+	return calls
+}
+
+// errorCallbacks list the OnReloadError callbacks for err
+func (w *ConfigWatcher) errorCallbacks(err error) []namedCallback {
+	calls := make([]namedCallback, 0, len(w.onError))
+	for i, callback := range w.onError {
+		calls = append(calls, namedCallback{
+			name: fmt.Sprintf("OnReloadError #%d", i+1),
+			fn:   func() error { callback(err); return nil },
+		})
+	}
+	return calls
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// DynamicValue is a live handle on a config key. Load is lock-free, and the
+// value is refreshed whenever the key changes: after each successful
+// ConfigWatcher reload and on Merge, Set or SetData.
+type DynamicValue[T any] struct {
+	cfg      *Config
+	key      string
+	def      T
+	value    atomic.Pointer[T]
+	mu       sync.Mutex
+	validate func(T) error
+	onChange []func(old, new T)
+	close    func()
+}
+
+// Dynamic create a handle on key, decoded into T like Unmarshal does,
+// using def when the key is missing or invalid
+func Dynamic[T any](cfg *Config, key string, def T) *DynamicValue[T] {
+	d := &DynamicValue[T]{cfg: cfg, key: key, def: def}
+	d.value.Store(&def)
+	d.refresh()
+	d.close = cfg.onKeysChanged(fmt.Sprintf("Dynamic '%s'", key), d.keysChanged)
+	return d
+}
+
+// Close stop tracking the config; Load keeps returning the last value
+func (d *DynamicValue[T]) Close() {
+	d.close()
+}
+
+// Load return the current value
+func (d *DynamicValue[T]) Load() T {
+	return *d.value.Load()
+}
+
+// Key return the config key of the handle
+func (d *DynamicValue[T]) Key() string {
+	return d.key
+}
+
+// WithValidation reject values failing fn; the previous value is kept
+func (d *DynamicValue[T]) WithValidation(fn func(T) error) *DynamicValue[T] {
+	d.mu.Lock()
+	d.validate = fn
+	d.mu.Unlock()
+	if err := d.refresh(); err != nil {
+		d.cfg.Logger().Error("dynamic config hook error", "key", d.key, "error", err)
+	}
+	return d
+}
+
+// OnChange register a hook called with the old and new value after each update
+func (d *DynamicValue[T]) OnChange(fn func(old, new T)) *DynamicValue[T] {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onChange = append(d.onChange, fn)
+	return d
+}
+
+func (d *DynamicValue[T]) keysChanged(keys []string) error {
+	if keys == nil {
+		return d.refresh()
+	}
+	resolved := d.cfg.ResolveAlias(d.key)
+	for _, key := range keys {
+		if relatedKeys(d.key, key) || relatedKeys(resolved, key) {
+			return d.refresh()
+		}
+	}
+	return nil
+}
+
+// refresh decode the current config value and store it when it changed.
+// Every OnChange hook runs even if one panics; their failures are returned.
+func (d *DynamicValue[T]) refresh() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	next, err := d.decode()
+	if err != nil {
+		d.cfg.Logger().Warn("invalid dynamic config value, keeping previous", "key", d.key, "error", err)
+		return nil
+	}
+
+	old := d.Load()
+	if reflect.DeepEqual(old, next) {
+		return nil
+	}
+	d.value.Store(&next)
+
+	var errs []error
+	for i, fn := range d.onChange {
+		name := fmt.Sprintf("Dynamic '%s' OnChange #%d", d.key, i+1)
+		if err := safeCall(name, func() error { fn(old, next); return nil }); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+func (d *DynamicValue[T]) decode() (T, error) {
+	raw, err := d.cfg.GetValue(d.key)
+	if err != nil {
+		return d.def, nil // Missing keys fall back to the default
+	}
+
+	var next T
+	var errs []error
+	decodeValue(raw, reflect.ValueOf(&next).Elem(), d.key, &errs)
+	if len(errs) > 0 {
+		return next, MultiError{Errors: errs}
+	}
+	if d.validate != nil {
+		if err := d.validate(next); err != nil {
+			return next, &ValidationError{Key: d.key, Message: err.Error()}
+		}
+	}
+	return next, nil
+}
+
+func (d *DynamicValue[T]) String() string {
+	return fmt.Sprint(d.cfg.RedactValue(d.key, d.Load()))
+}
@@ -0,0 +1,365 @@
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	encryptedPrefix = "ENC["
+	encryptedCipher = "AES256_GCM"
+	// KeySize is the size in bytes of keyring keys (AES-256)
+	KeySize = 32
+)
+
+// DecryptError report an encrypted value that could not be decrypted for a key
+type DecryptError struct {
+	Key string
+	Err error
+}
+
+func (e *DecryptError) Error() string {
+	return fmt.Sprintf("error decrypting value for key '%s': %v", e.Key, e.Err)
+}
+
+func (e *DecryptError) Unwrap() error {
+	return e.Err
+}
+
+// Keyring hold the keys used to decrypt inline values written as
+// "ENC[AES256_GCM,data:...,iv:...,tag:...,type:str,kid:...]". Several key
+// IDs can be loaded at once so keys can be rotated. Like SOPS, each value
+// is bound to the dot-notation key it is stored under and to its type and
+// key ID, so it cannot be moved to another key or edited unnoticed.
+type Keyring struct {
+	mu      sync.RWMutex
+	keys    map[string][]byte
+	primary string
+}
+
+// NewKeyring create an empty keyring
+func NewKeyring() *Keyring {
+	return &Keyring{keys: make(map[string][]byte)}
+}
+
+// GenerateKey create a random key suitable for the keyring
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// LoadKeyringFromFile load keys from a JSON file:
+//
+//	{"primary": "v2", "keys": {"v1": "<base64>", "v2": "<base64>"}}
+func LoadKeyringFromFile(path string) (*Keyring, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening keyring file: %w", err)
+	}
+
+	var file struct {
+		Primary string            `json:"primary"`
+		Keys    map[string]string `json:"keys"`
+	}
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("error decoding keyring file: %w", err)
+	}
+
+	ids := make([]string, 0, len(file.Keys))
+	for id := range file.Keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	k := NewKeyring()
+	for _, id := range ids {
+		if err := k.addEncodedKey(id, file.Keys[id]); err != nil {
+			return nil, err
+		}
+	}
+	if file.Primary != "" {
+		if err := k.SetPrimary(file.Primary); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// LoadKeyringFromEnv load keys from an env var holding "id:<base64>" pairs
+// separated by commas; the first key is the primary one
+func LoadKeyringFromEnv(name string) (*Keyring, error) {
+	value, exists := os.LookupEnv(name)
+	if !exists || value == "" {
+		return nil, &KeyError{Key: name}
+	}
+
+	k := NewKeyring()
+	for _, entry := range strings.Split(value, ",") {
+		id, encoded, found := strings.Cut(strings.TrimSpace(entry), ":")
+		if !found {
+			return nil, fmt.Errorf("invalid keyring entry in %s: expected id:key", name)
+		}
+		if err := k.addEncodedKey(id, encoded); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// AddKey add a key; the first key added becomes the primary one
+func (k *Keyring) AddKey(id string, key []byte) error {
+	if id == "" {
+		return fmt.Errorf("key id must not be empty")
+	}
+	if len(key) != KeySize {
+		return fmt.Errorf("invalid key size for '%s': expected %d bytes, got %d", id, KeySize, len(key))
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[id] = append([]byte(nil), key...)
+	if k.primary == "" {
+		k.primary = id
+	}
+	return nil
+}
+
+// SetPrimary select the key used by Encrypt
+func (k *Keyring) SetPrimary(id string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, exists := k.keys[id]; !exists {
+		return &KeyError{Key: id}
+	}
+	k.primary = id
+	return nil
+}
+
+// Encrypt encrypt a string stored under the config key path with the
+// primary key
+func (k *Keyring) Encrypt(path, plaintext string) (string, error) {
+	k.mu.RLock()
+	primary := k.primary
+	k.mu.RUnlock()
+	return k.EncryptWithKey(primary, path, plaintext)
+}
+
+// EncryptValue encrypt a string, int, float or bool stored under path with
+// the primary key, keeping its type so it is restored on decryption
+func (k *Keyring) EncryptValue(path string, value interface{}) (string, error) {
+	k.mu.RLock()
+	primary := k.primary
+	k.mu.RUnlock()
+
+	var plaintext, typ string
+	switch v := value.(type) {
+	case string:
+		plaintext, typ = v, "str"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		plaintext, typ = fmt.Sprintf("%d", v), "int"
+	case float32, float64:
+		plaintext, _ = ConvertToString(v)
+		typ = "float"
+	case bool:
+		plaintext, typ = strconv.FormatBool(v), "bool"
+	default:
+		return "", &TypeError{Key: "value", Expected: "string, int, float or bool", Actual: fmt.Sprintf("%T", value)}
+	}
+	return k.encrypt(primary, path, plaintext, typ)
+}
+
+// EncryptWithKey encrypt a string stored under path with the given key ID
+func (k *Keyring) EncryptWithKey(id, path, plaintext string) (string, error) {
+	return k.encrypt(id, path, plaintext, "str")
+}
+
+// Decrypt decrypt an "ENC[...]" value stored under the config key path,
+// restoring its original type
+func (k *Keyring) Decrypt(path, value string) (interface{}, error) {
+	return k.decrypt([]string{path}, value)
+}
+
+// decrypt decrypt a value encrypted under any of paths
+func (k *Keyring) decrypt(paths []string, value string) (interface{}, error) {
+	fields, err := parseEncrypted(value)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err1 := base64.StdEncoding.DecodeString(fields["data"])
+	iv, err2 := base64.StdEncoding.DecodeString(fields["iv"])
+	tag, err3 := base64.StdEncoding.DecodeString(fields["tag"])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return nil, fmt.Errorf("invalid base64 in encrypted value")
+	}
+	sealed := append(data, tag...)
+
+	k.mu.RLock()
+	candidates := make(map[string][]byte)
+	if kid := fields["kid"]; kid != "" {
+		key, exists := k.keys[kid]
+		if !exists {
+			k.mu.RUnlock()
+			return nil, fmt.Errorf("unknown key id '%s'", kid)
+		}
+		candidates[kid] = key
+	} else {
+		for id, key := range k.keys {
+			candidates[id] = key
+		}
+	}
+	k.mu.RUnlock()
+
+	for _, key := range candidates {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(iv) != gcm.NonceSize() {
+			return nil, fmt.Errorf("invalid iv size")
+		}
+		for _, path := range paths {
+			plaintext, err := gcm.Open(nil, iv, sealed, additionalData(path, fields["type"], fields["kid"]))
+			if err == nil {
+				return restoreEncryptedType(string(plaintext), fields["type"])
+			}
+		}
+	}
+	return nil, fmt.Errorf("no key in keyring can decrypt value (wrong key, config key or edited value)")
+}
+
+// Process replace every "ENC[...]" value in the tree with its decrypted value
+func (k *Keyring) Process(data map[string]interface{}) error {
+	return k.processKeys(data, nil)
+}
+
+// processKeys decrypt like Process, also accepting values encrypted under
+// an old key that an alias moved them from
+func (k *Keyring) processKeys(data map[string]interface{}, aliasesOf func(key string) []string) error {
+	errs := walkStrings(data, "", func(key, value string) (interface{}, error) {
+		if !IsEncrypted(value) {
+			return value, nil
+		}
+		paths := []string{key}
+		if aliasesOf != nil {
+			paths = append(paths, aliasesOf(key)...)
+		}
+		decrypted, err := k.decrypt(paths, value)
+		if err != nil {
+			return value, &DecryptError{Key: key, Err: err}
+		}
+		return decrypted, nil
+	})
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// IsEncrypted report if value is an inline encrypted value
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix) && strings.HasSuffix(value, "]")
+}
+
+func (k *Keyring) encrypt(id, path, plaintext, typ string) (string, error) {
+	k.mu.RLock()
+	key, exists := k.keys[id]
+	k.mu.RUnlock()
+	if !exists {
+		return "", &KeyError{Key: id}
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), additionalData(path, typ, id))
+	data, tag := sealed[:len(sealed)-gcm.Overhead()], sealed[len(sealed)-gcm.Overhead():]
+
+	return fmt.Sprintf("%s%s,data:%s,iv:%s,tag:%s,type:%s,kid:%s]",
+		encryptedPrefix, encryptedCipher,
+		base64.StdEncoding.EncodeToString(data),
+		base64.StdEncoding.EncodeToString(iv),
+		base64.StdEncoding.EncodeToString(tag),
+		typ, id), nil
+}
+
+// additionalData is the authenticated data of a value: its config key,
+// type and key ID
+func additionalData(path, typ, kid string) []byte {
+	return []byte(strings.Join([]string{encryptedCipher, path, typ, kid}, "\x00"))
+}
+
+func (k *Keyring) addEncodedKey(id, encoded string) error {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return fmt.Errorf("invalid base64 key '%s': %w", id, err)
+	}
+	return k.AddKey(id, key)
+}
+
+// parseEncrypted split "ENC[AES256_GCM,data:...,iv:...]" into its fields
+func parseEncrypted(value string) (map[string]string, error) {
+	if !IsEncrypted(value) {
+		return nil, fmt.Errorf("value is not encrypted")
+	}
+	parts := strings.Split(value[len(encryptedPrefix):len(value)-1], ",")
+	if parts[0] != encryptedCipher {
+		return nil, fmt.Errorf("unsupported cipher '%s'", parts[0])
+	}
+
+	fields := make(map[string]string)
+	for _, part := range parts[1:] {
+		name, val, found := strings.Cut(part, ":")
+		if !found {
+			return nil, fmt.Errorf("invalid encrypted field '%s'", part)
+		}
+		fields[name] = val
+	}
+	for _, required := range []string{"data", "iv", "tag"} {
+		if _, exists := fields[required]; !exists {
+			return nil, fmt.Errorf("missing encrypted field '%s'", required)
+		}
+	}
+	return fields, nil
+}
+
+func restoreEncryptedType(plaintext, typ string) (interface{}, error) {
+	switch typ {
+	case "", "str":
+		return plaintext, nil
+	case "int":
+		return strconv.ParseInt(plaintext, 10, 64)
+	case "float":
+		return strconv.ParseFloat(plaintext, 64)
+	case "bool":
+		return strconv.ParseBool(plaintext)
+	default:
+		return nil, fmt.Errorf("unsupported encrypted type '%s'", typ)
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -35,8 +35,11 @@ func (e *EnvLoader) Load(c *Config) error {
 		configKey = strings.ToLower(configKey)
 		configKey = strings.ReplaceAll(configKey, "_", ".")
 
-		// Asign value
+		// Asign value, renaming aliased keys
+		configKey = c.migrateKey(configKey, "env "+key)
 		c.Data[configKey] = value
+		c.recordOrigin(configKey, "env "+key)
+		c.notifyChanged([]string{configKey})
 	}
 	return nil
 }
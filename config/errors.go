@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 type KeyError struct {
@@ -48,3 +49,59 @@ func (m MultiError) Error() string {
 	sb.WriteString("]")
 	return sb.String()
 }
+
+// Unwrap expose the errors to errors.Is and errors.As
+func (m MultiError) Unwrap() []error {
+	return m.Errors
+}
+
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid value for key '%s': %s", e.Key, e.Message)
+}
+
+type RuleError struct {
+	Rule    string
+	Keys    []string
+	Message string
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("rule '%s' failed for keys [%s]: %s", e.Rule, strings.Join(e.Keys, ", "), e.Message)
+}
+
+type UnknownKeyError struct {
+	Key        string
+	Source     string
+	Suggestion string
+}
+
+func (e *UnknownKeyError) Error() string {
+	msg := fmt.Sprintf("unknown key '%s'", e.Key)
+	if e.Source != "" {
+		msg += fmt.Sprintf(" (from %s)", e.Source)
+	}
+	if e.Suggestion != "" {
+		msg += fmt.Sprintf(", did you mean '%s'?", e.Suggestion)
+	}
+	return msg
+}
+
+// CallbackError when a watcher callback panicked or exceeded its timeout
+type CallbackError struct {
+	Callback string
+	Panic    interface{}
+	Stack    []byte
+	Timeout  time.Duration
+}
+
+func (e *CallbackError) Error() string {
+	if e.Timeout > 0 {
+		return fmt.Sprintf("callback %s timed out after %s", e.Callback, e.Timeout)
+	}
+	return fmt.Sprintf("callback %s panicked: %v", e.Callback, e.Panic)
+}
@@ -34,6 +34,21 @@ func (c *Config) GetBool(key string) (bool, error) {
 }
 
 func (c *Config) GetValue(key string) (interface{}, error) {
+	val, err := c.getValue(key)
+	if err != nil && c.loadMigrations() != nil {
+		if resolved := c.ResolveAlias(key); resolved != key {
+			if val, aliasErr := c.getValue(resolved); aliasErr == nil {
+				return val, nil
+			}
+		}
+	}
+	return val, err
+}
+
+func (c *Config) getValue(key string) (interface{}, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	current := c.Data
 	parts := strings.Split(key, ".")
 
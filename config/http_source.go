@@ -0,0 +1,182 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPSource load config from a remote HTTP(S) endpoint.
+// It honours ETag/If-None-Match and Cache-Control, and implements PollingSource
+// so a ConfigWatcher can poll it for changes.
+type HTTPSource struct {
+	url     string
+	headers http.Header
+	client  *http.Client
+
+	mu       sync.Mutex
+	data     map[string]interface{}
+	etag     string
+	expires  time.Time
+	noStore  bool
+	consumed bool
+}
+
+// NewHTTPSource create a new HTTP source for url
+func NewHTTPSource(url string) *HTTPSource {
+	return &HTTPSource{
+		url:     url,
+		headers: make(http.Header),
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// WithHeader add a custom header to every request
+func (s *HTTPSource) WithHeader(key, value string) *HTTPSource {
+	s.headers.Add(key, value)
+	return s
+}
+
+// WithBasicAuth set basic auth credentials
+func (s *HTTPSource) WithBasicAuth(username, password string) *HTTPSource {
+	req := &http.Request{Header: make(http.Header)}
+	req.SetBasicAuth(username, password)
+	s.headers.Set("Authorization", req.Header.Get("Authorization"))
+	return s
+}
+
+// WithBearerToken set a bearer token for authorization
+func (s *HTTPSource) WithBearerToken(token string) *HTTPSource {
+	s.headers.Set("Authorization", "Bearer "+token)
+	return s
+}
+
+// WithClient set the HTTP client used for requests
+func (s *HTTPSource) WithClient(client *http.Client) *HTTPSource {
+	s.client = client
+	return s
+}
+
+// Load return the remote config, using the cached copy while it is fresh
+func (s *HTTPSource) Load() (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data == nil || (s.consumed && !s.fresh()) {
+		if _, err := s.fetch(); err != nil {
+			return nil, err
+		}
+	}
+	s.consumed = true
+	return cloneMap(s.data), nil
+}
+
+// Changed revalidate the remote config and report if it changed
+func (s *HTTPSource) Changed() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.data != nil && s.fresh() {
+		return false, nil
+	}
+	return s.fetch()
+}
+
+func (s *HTTPSource) fresh() bool {
+	return !s.noStore && time.Now().Before(s.expires)
+}
+
+// fetch request the endpoint and report if the content changed
+func (s *HTTPSource) fetch() (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, s.url, nil)
+	if err != nil {
+		return false, fmt.Errorf("error creating request: %w", err)
+	}
+	for key, values := range s.headers {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
+	}
+	if s.etag != "" && s.data != nil && !s.noStore {
+		req.Header.Set("If-None-Match", s.etag)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("error fetching %s: %w", s.url, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		s.applyCacheControl(resp.Header.Get("Cache-Control"))
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, s.url)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("error reading %s: %w", s.url, err)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	codec, ok := CodecFor(contentType)
+	if !ok && json.Valid(body) {
+		// Servers without a Content-Type get "text/plain" sniffed by net/http
+		codec, ok = JSONCodec{}, true
+	}
+	if !ok {
+		return false, fmt.Errorf("no codec registered for content type %q", contentType)
+	}
+	data, err := codec.Decode(body)
+	if err != nil {
+		return false, err
+	}
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+
+	changed := s.data == nil || !reflect.DeepEqual(s.data, data)
+	s.data = data
+	s.etag = resp.Header.Get("ETag")
+	s.applyCacheControl(resp.Header.Get("Cache-Control"))
+	if changed {
+		s.consumed = false
+	}
+	return changed, nil
+}
+
+// applyCacheControl update freshness from a Cache-Control header
+func (s *HTTPSource) applyCacheControl(header string) {
+	maxAge, noCache, noStore := 0, false, false
+	for _, directive := range strings.Split(header, ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			noStore = true
+		case directive == "no-cache":
+			noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				maxAge = seconds
+			}
+		}
+	}
+
+	s.noStore = noStore
+	s.expires = time.Time{}
+	if !noCache && maxAge > 0 {
+		s.expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	}
+}
@@ -0,0 +1,398 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSONSchema validate configs against a JSON Schema (draft 2020-12 subset):
+// type, required, properties, additionalProperties, items, enum, const,
+// numeric and string constraints, format, $ref, allOf, anyOf and oneOf.
+// Values from env vars are strings, so numeric and boolean strings are
+// accepted for "number", "integer" and "boolean".
+type JSONSchema struct {
+	root     map[string]interface{}
+	mu       sync.Mutex
+	patterns map[string]*regexp.Regexp
+}
+
+// LoadJSONSchema load a JSON Schema file
+func LoadJSONSchema(filePath string) (*JSONSchema, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("error opening JSON schema: %w", err)
+	}
+	return ParseJSONSchema(content)
+}
+
+// ParseJSONSchema parse a JSON Schema document
+func ParseJSONSchema(data []byte) (*JSONSchema, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("error decoding JSON schema: %w", err)
+	}
+	return &JSONSchema{root: root, patterns: make(map[string]*regexp.Regexp)}, nil
+}
+
+// ValidateConfig validate the config data, returning a MultiError with
+// every violation keyed by dot-notation
+func (s *JSONSchema) ValidateConfig(c *Config) error {
+	errs := s.validate(s.root, c.data(), "", 0)
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// maxRefDepth stop recursive $ref cycles
+const maxRefDepth = 64
+
+func (s *JSONSchema) validate(schema map[string]interface{}, val interface{}, key string, depth int) []error {
+	if depth > maxRefDepth {
+		return []error{&ValidationError{Key: displayKey(key), Message: "schema $ref nesting too deep"}}
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := s.resolveRef(ref)
+		if err != nil {
+			return []error{&ValidationError{Key: displayKey(key), Message: err.Error()}}
+		}
+		if errs := s.validate(target, val, key, depth+1); len(errs) > 0 {
+			return errs
+		}
+	}
+
+	if types, ok := schema["type"]; ok && !matchesAnyType(types, val) {
+		return []error{&TypeError{Key: displayKey(key), Expected: typeNames(types), Actual: jsonTypeOf(val)}}
+	}
+
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Key: displayKey(key), Message: fmt.Sprintf(format, args...)})
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok && !jsonEnumContains(enum, val) {
+		invalid("must be one of %v", enum)
+	}
+	if constVal, ok := schema["const"]; ok && !jsonEqual(constVal, val) {
+		invalid("must be %v", constVal)
+	}
+
+	if number, ok := jsonNumber(val); ok {
+		s.validateNumber(schema, number, invalid)
+	}
+	if str, ok := val.(string); ok {
+		s.validateString(schema, str, invalid)
+	}
+
+	switch v := val.(type) {
+	case map[string]interface{}:
+		errs = append(errs, s.validateObject(schema, v, key, depth)...)
+	case []interface{}:
+		errs = append(errs, s.validateArray(schema, v, key, depth)...)
+	}
+
+	if all, ok := schema["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			if subSchema, ok := sub.(map[string]interface{}); ok {
+				errs = append(errs, s.validate(subSchema, val, key, depth+1)...)
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]interface{}); ok && s.countMatches(anyOf, val, key, depth) == 0 {
+		invalid("must match at least one schema in anyOf")
+	}
+	if oneOf, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := s.countMatches(oneOf, val, key, depth); matches != 1 {
+			invalid("must match exactly one schema in oneOf (matched %d)", matches)
+		}
+	}
+	return errs
+}
+
+func (s *JSONSchema) validateNumber(schema map[string]interface{}, number float64, invalid func(string, ...interface{})) {
+	if min, ok := jsonNumber(schema["minimum"]); ok && number < min {
+		invalid("must be >= %v", min)
+	}
+	if max, ok := jsonNumber(schema["maximum"]); ok && number > max {
+		invalid("must be <= %v", max)
+	}
+	if min, ok := jsonNumber(schema["exclusiveMinimum"]); ok && number <= min {
+		invalid("must be > %v", min)
+	}
+	if max, ok := jsonNumber(schema["exclusiveMaximum"]); ok && number >= max {
+		invalid("must be < %v", max)
+	}
+	if multiple, ok := jsonNumber(schema["multipleOf"]); ok && multiple > 0 {
+		if quotient := number / multiple; quotient != math.Trunc(quotient) {
+			invalid("must be a multiple of %v", multiple)
+		}
+	}
+}
+
+func (s *JSONSchema) validateString(schema map[string]interface{}, str string, invalid func(string, ...interface{})) {
+	length := len([]rune(str))
+	if min, ok := jsonNumber(schema["minLength"]); ok && float64(length) < min {
+		invalid("length must be >= %v", min)
+	}
+	if max, ok := jsonNumber(schema["maxLength"]); ok && float64(length) > max {
+		invalid("length must be <= %v", max)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := s.compile(pattern)
+		if err != nil {
+			invalid("invalid pattern: %v", err)
+		} else if !re.MatchString(str) {
+			invalid("must match pattern %s", pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok {
+		var msg string
+		switch format {
+		case "uri", "url":
+			msg = checkFormat(FormatURL, str)
+		case "hostname":
+			msg = checkFormat(FormatHostname, str)
+		case "duration":
+			msg = checkFormat(FormatDuration, str)
+		}
+		if msg != "" {
+			invalid("%s", msg)
+		}
+	}
+}
+
+func (s *JSONSchema) validateObject(schema map[string]interface{}, obj map[string]interface{}, key string, depth int) []error {
+	var errs []error
+
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if field, ok := name.(string); ok {
+				if _, exists := obj[field]; !exists {
+					errs = append(errs, &KeyError{Key: joinKey(key, field)})
+				}
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		childKey := joinKey(key, name)
+		if propSchema, ok := properties[name].(map[string]interface{}); ok {
+			errs = append(errs, s.validate(propSchema, obj[name], childKey, depth+1)...)
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				errs = append(errs, &ValidationError{Key: childKey, Message: "additional property is not allowed"})
+			}
+		case map[string]interface{}:
+			errs = append(errs, s.validate(additional, obj[name], childKey, depth+1)...)
+		}
+	}
+	return errs
+}
+
+func (s *JSONSchema) validateArray(schema map[string]interface{}, list []interface{}, key string, depth int) []error {
+	var errs []error
+	if min, ok := jsonNumber(schema["minItems"]); ok && float64(len(list)) < min {
+		errs = append(errs, &ValidationError{Key: displayKey(key), Message: fmt.Sprintf("must have at least %v items", min)})
+	}
+	if max, ok := jsonNumber(schema["maxItems"]); ok && float64(len(list)) > max {
+		errs = append(errs, &ValidationError{Key: displayKey(key), Message: fmt.Sprintf("must have at most %v items", max)})
+	}
+	if items, ok := schema["items"].(map[string]interface{}); ok {
+		for i, item := range list {
+			errs = append(errs, s.validate(items, item, fmt.Sprintf("%s[%d]", key, i), depth+1)...)
+		}
+	}
+	return errs
+}
+
+func (s *JSONSchema) countMatches(schemas []interface{}, val interface{}, key string, depth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		if subSchema, ok := sub.(map[string]interface{}); ok && len(s.validate(subSchema, val, key, depth+1)) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// resolveRef resolve local references like "#/$defs/port" (JSON pointer)
+func (s *JSONSchema) resolveRef(ref string) (map[string]interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+
+	var current interface{} = s.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#"), "/") {
+		if token == "" {
+			continue
+		}
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		node, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if current, ok = node[token]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+
+	target, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unresolvable $ref %q", ref)
+	}
+	return target, nil
+}
+
+func (s *JSONSchema) compile(pattern string) (*regexp.Regexp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if re, ok := s.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	s.patterns[pattern] = re
+	return re, nil
+}
+
+func matchesAnyType(types interface{}, val interface{}) bool {
+	switch t := types.(type) {
+	case string:
+		return matchesType(t, val)
+	case []interface{}:
+		for _, name := range t {
+			if str, ok := name.(string); ok && matchesType(str, val) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func matchesType(name string, val interface{}) bool {
+	switch name {
+	case "object":
+		_, ok := val.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := val.([]interface{})
+		return ok
+	case "string":
+		_, ok := val.(string)
+		return ok
+	case "boolean":
+		if _, ok := val.(bool); ok {
+			return true
+		}
+		if str, ok := val.(string); ok {
+			_, err := strconv.ParseBool(str)
+			return err == nil
+		}
+		return false
+	case "number":
+		_, ok := jsonNumber(val)
+		return ok
+	case "integer":
+		number, ok := jsonNumber(val)
+		return ok && number == math.Trunc(number)
+	case "null":
+		return val == nil
+	}
+	return false
+}
+
+// jsonNumber convert numeric values (and numeric strings) to float64
+func jsonNumber(val interface{}) (float64, bool) {
+	if val == nil {
+		return 0, false
+	}
+	return toFloat(val)
+}
+
+func jsonTypeOf(val interface{}) string {
+	switch val.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	}
+	if _, ok := jsonNumber(val); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", val)
+}
+
+func typeNames(types interface{}) string {
+	if list, ok := types.([]interface{}); ok {
+		names := make([]string, len(list))
+		for i, name := range list {
+			names[i] = fmt.Sprint(name)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func jsonEnumContains(enum []interface{}, val interface{}) bool {
+	for _, allowed := range enum {
+		if jsonEqual(allowed, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonEqual compare values treating all numeric types as equal by value
+func jsonEqual(a, b interface{}) bool {
+	if _, isString := a.(string); !isString {
+		if na, ok := jsonNumber(a); ok {
+			if _, isString := b.(string); !isString {
+				nb, ok := jsonNumber(b)
+				return ok && na == nb
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func joinKey(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+func displayKey(key string) string {
+	if key == "" {
+		return "(root)"
+	}
+	return key
+}
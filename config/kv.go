@@ -0,0 +1,200 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// KVEventType define the kind of change in a key-value store
+type KVEventType int
+
+const (
+	// KVPut is emitted when a key is created or updated
+	KVPut KVEventType = iota
+	// KVDelete is emitted when a key is removed
+	KVDelete
+)
+
+// KVPair is a single entry of a key-value store
+type KVPair struct {
+	Key      string
+	Value    []byte
+	Revision int64
+}
+
+// KVEvent describe a change in a key-value store
+type KVEvent struct {
+	Type     KVEventType
+	Pair     KVPair
+	Revision int64
+}
+
+// KVProvider define the operations needed to back config by a
+// key-value store (etcd/Consul-style) under a key prefix
+type KVProvider interface {
+	// List return every pair under prefix and the current store revision
+	List(ctx context.Context, prefix string) ([]KVPair, int64, error)
+	// Get return a single pair, or a *KeyError if it does not exist
+	Get(ctx context.Context, key string) (KVPair, error)
+	// Watch stream changes under prefix starting at fromRevision.
+	// The channel is closed when ctx is cancelled.
+	Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan KVEvent, error)
+}
+
+// KVSource adapt a KVProvider to the Source interface, mapping flat
+// paths like "app/db/host" under the prefix into nested keys ("db.host")
+type KVSource struct {
+	provider   KVProvider
+	prefix     string
+	separator  string
+	decodeJSON bool
+
+	mu       sync.Mutex
+	revision int64
+	events   <-chan KVEvent
+	cancel   context.CancelFunc
+}
+
+// NewKVSource create a source reading every key under prefix
+func NewKVSource(provider KVProvider, prefix string) *KVSource {
+	return &KVSource{
+		provider:  provider,
+		prefix:    prefix,
+		separator: "/",
+	}
+}
+
+// WithSeparator set the path separator used by the store (default "/")
+func (s *KVSource) WithSeparator(separator string) *KVSource {
+	s.separator = separator
+	return s
+}
+
+// WithJSONValues decode values that are valid JSON instead of keeping them as strings
+func (s *KVSource) WithJSONValues() *KVSource {
+	s.decodeJSON = true
+	return s
+}
+
+// Load list the prefix and build the config tree
+func (s *KVSource) Load() (map[string]interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pairs, revision, err := s.provider.List(context.Background(), s.prefix)
+	if err != nil {
+		return nil, err
+	}
+	s.revision = revision
+	s.startWatch()
+
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].Key < pairs[j].Key })
+
+	data := make(map[string]interface{})
+	for _, pair := range pairs {
+		parts := s.keyParts(pair.Key)
+		if len(parts) == 0 {
+			continue
+		}
+		setNested(data, parts, s.decodeValue(pair.Value))
+	}
+	return data, nil
+}
+
+// Changed report if the store changed since the last Load, using the
+// watch stream when available and the list revision otherwise
+func (s *KVSource) Changed() (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.events != nil {
+		changed := false
+		for {
+			select {
+			case event, ok := <-s.events:
+				if !ok {
+					s.events = nil
+					return s.revisionChanged()
+				}
+				if event.Revision > s.revision {
+					changed = true
+				}
+			default:
+				return changed, nil
+			}
+		}
+	}
+	return s.revisionChanged()
+}
+
+// Close stop the background watch
+func (s *KVSource) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.events = nil
+}
+
+func (s *KVSource) revisionChanged() (bool, error) {
+	_, revision, err := s.provider.List(context.Background(), s.prefix)
+	if err != nil {
+		return false, err
+	}
+	return revision != s.revision, nil
+}
+
+// startWatch open the watch stream once; providers without watch support fall back to polling
+func (s *KVSource) startWatch() {
+	if s.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	events, err := s.provider.Watch(ctx, s.prefix, s.revision+1)
+	if err != nil {
+		cancel()
+		return
+	}
+	s.events = events
+	s.cancel = cancel
+}
+
+func (s *KVSource) keyParts(key string) []string {
+	key = strings.TrimPrefix(key, s.prefix)
+	var parts []string
+	for _, part := range strings.Split(key, s.separator) {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+func (s *KVSource) decodeValue(value []byte) interface{} {
+	if s.decodeJSON {
+		var decoded interface{}
+		if err := json.Unmarshal(value, &decoded); err == nil {
+			return decoded
+		}
+	}
+	return string(value)
+}
+
+// setNested set value at parts, replacing scalars that are in the way
+func setNested(data map[string]interface{}, parts []string, value interface{}) {
+	current := data
+	for _, part := range parts[:len(parts)-1] {
+		next, ok := current[part].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			current[part] = next
+		}
+		current = next
+	}
+	current[parts[len(parts)-1]] = value
+}
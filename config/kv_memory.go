@@ -0,0 +1,167 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// MemoryKV is an in-memory KVProvider, useful for tests and as a
+// reference for real backends
+type MemoryKV struct {
+	mu       sync.Mutex
+	pairs    map[string]KVPair
+	history  []KVEvent
+	revision int64
+	watches  []*memoryWatch
+}
+
+// NewMemoryKV create an empty in-memory store
+func NewMemoryKV() *MemoryKV {
+	return &MemoryKV{pairs: make(map[string]KVPair)}
+}
+
+// Put create or update a key and return the new revision
+func (m *MemoryKV) Put(key, value string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.revision++
+	pair := KVPair{Key: key, Value: []byte(value), Revision: m.revision}
+	m.pairs[key] = pair
+	m.publish(KVEvent{Type: KVPut, Pair: pair, Revision: m.revision})
+	return m.revision
+}
+
+// Delete remove a key and return the new revision
+func (m *MemoryKV) Delete(key string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pair, exists := m.pairs[key]
+	if !exists {
+		return m.revision
+	}
+	m.revision++
+	delete(m.pairs, key)
+	pair.Revision = m.revision
+	m.publish(KVEvent{Type: KVDelete, Pair: pair, Revision: m.revision})
+	return m.revision
+}
+
+// List return every pair under prefix
+func (m *MemoryKV) List(ctx context.Context, prefix string) ([]KVPair, int64, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var pairs []KVPair
+	for key, pair := range m.pairs {
+		if strings.HasPrefix(key, prefix) {
+			pairs = append(pairs, pair)
+		}
+	}
+	return pairs, m.revision, nil
+}
+
+// Get return a single pair
+func (m *MemoryKV) Get(ctx context.Context, key string) (KVPair, error) {
+	if err := ctx.Err(); err != nil {
+		return KVPair{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	pair, exists := m.pairs[key]
+	if !exists {
+		return KVPair{}, &KeyError{Key: key}
+	}
+	return pair, nil
+}
+
+// Watch stream changes under prefix, replaying history from fromRevision
+func (m *MemoryKV) Watch(ctx context.Context, prefix string, fromRevision int64) (<-chan KVEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := &memoryWatch{
+		prefix: prefix,
+		notify: make(chan struct{}, 1),
+		out:    make(chan KVEvent),
+	}
+	for _, event := range m.history {
+		if event.Revision >= fromRevision {
+			w.push(event)
+		}
+	}
+	m.watches = append(m.watches, w)
+	go w.run(ctx)
+	return w.out, nil
+}
+
+func (m *MemoryKV) publish(event KVEvent) {
+	m.history = append(m.history, event)
+	for _, w := range m.watches {
+		w.push(event)
+	}
+}
+
+// memoryWatch queue events so publishers never block on slow readers
+type memoryWatch struct {
+	prefix  string
+	mu      sync.Mutex
+	pending []KVEvent
+	closed  bool
+	notify  chan struct{}
+	out     chan KVEvent
+}
+
+func (w *memoryWatch) push(event KVEvent) {
+	if !strings.HasPrefix(event.Pair.Key, w.prefix) {
+		return
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return
+	}
+	w.pending = append(w.pending, event)
+	w.mu.Unlock()
+
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (w *memoryWatch) run(ctx context.Context) {
+	defer func() {
+		w.mu.Lock()
+		w.closed = true
+		w.pending = nil
+		w.mu.Unlock()
+		close(w.out)
+	}()
+	for {
+		w.mu.Lock()
+		events := w.pending
+		w.pending = nil
+		w.mu.Unlock()
+
+		for _, event := range events {
+			select {
+			case w.out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		select {
+		case <-w.notify:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// SaveLastGood write the config data to filePath as JSON, atomically and
+// with 0600 permissions. Configs built with processors are saved as they
+// were before processing, so "secret://" references and ENC[...] values
+// stay unresolved on disk.
+func SaveLastGood(c *Config, filePath string) error {
+	content, err := json.MarshalIndent(c.persisted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding last good config: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), "."+filepath.Base(filePath)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("error saving last good config: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error saving last good config: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error saving last good config: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return fmt.Errorf("error saving last good config: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("error saving last good config: %w", err)
+	}
+	return nil
+}
+
+// persisted return the tree to save: the unprocessed one when processors ran
+func (c *Config) persisted() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.raw != nil {
+		return c.raw
+	}
+	return c.Data
+}
+
+// LoadLastGood load a config saved by SaveLastGood. Processors are not run:
+// a ConfigBuilder falling back to it with WithLastGood runs its own.
+func LoadLastGood(filePath string) (*Config, error) {
+	cfg := NewConfig()
+	if err := cfg.LoadFromJSON(filePath); err != nil {
+		return nil, fmt.Errorf("error loading last good config: %w", err)
+	}
+	return cfg, nil
+}
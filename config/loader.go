@@ -19,6 +19,8 @@ func (c *Config) LoadFromJSON(filePath string) error {
 		return fmt.Errorf("error decoding JSON: %w", err)
 	}
 
-	c.Merge(&Config{Data: tempData})
+	loaded := &Config{Data: tempData}
+	loaded.recordOrigins(tempData, "", filePath)
+	c.Merge(loaded)
 	return nil
 }
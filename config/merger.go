@@ -2,9 +2,38 @@ package config
 
 // Merge combine other config
 func (c *Config) Merge(other *Config) {
-	if other != nil {
-		mergeMaps(c.Data, other.Data)
+	if other == nil {
+		return
 	}
+
+	data, origins := other.Data, other.origins
+	if c.loadMigrations() != nil {
+		// Rewrite aliased keys on a copy so values keep their precedence
+		data = cloneMap(other.Data)
+		origins = make(map[string]string, len(other.origins))
+		for key, origin := range other.origins {
+			origins[key] = origin
+		}
+		c.migrateFrom(data, origins)
+	}
+
+	c.mu.Lock()
+	mergeMaps(c.Data, data)
+	if c.raw != nil {
+		mergeMaps(c.raw, cloneMap(data)) // Keep the saved tree in step
+	}
+	for key, origin := range origins {
+		c.recordOrigin(key, origin)
+	}
+	c.mu.Unlock()
+
+	leaves := make(map[string]interface{})
+	flattenLeaves(data, "", leaves)
+	keys := make([]string, 0, len(leaves))
+	for key := range leaves {
+		keys = append(keys, key)
+	}
+	c.notifyChanged(keys)
 }
 
 func mergeMaps(dest, src map[string]interface{}) {
@@ -20,3 +49,30 @@ func mergeMaps(dest, src map[string]interface{}) {
 		dest[key] = srcVal
 	}
 }
+
+// cloneMap deep copy a config tree so merges never share nested maps or lists
+func cloneMap(src map[string]interface{}) map[string]interface{} {
+	if src == nil {
+		return nil
+	}
+	dest := make(map[string]interface{}, len(src))
+	for key, val := range src {
+		dest[key] = cloneValue(val)
+	}
+	return dest
+}
+
+func cloneValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		return cloneMap(v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = cloneValue(item)
+		}
+		return list
+	default:
+		return v
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"sort"
+	"strings"
+)
+
+// Origin return the source (file path or env var) that last set key or,
+// for a nested map, one of its keys; "" when unknown
+func (c *Config) Origin(key string) string {
+	if origin, exists := c.origins[key]; exists {
+		return origin
+	}
+
+	var nested []string
+	for recorded := range c.origins {
+		if strings.HasPrefix(recorded, key+".") {
+			nested = append(nested, recorded)
+		}
+	}
+	if len(nested) > 0 {
+		sort.Strings(nested)
+		return c.origins[nested[0]]
+	}
+
+	for parts := strings.Split(key, "."); len(parts) > 1; {
+		parts = parts[:len(parts)-1]
+		if origin, exists := c.origins[strings.Join(parts, ".")]; exists {
+			return origin
+		}
+	}
+	return ""
+}
+
+func (c *Config) recordOrigin(key, origin string) {
+	if c.origins == nil {
+		c.origins = make(map[string]string)
+	}
+	c.origins[key] = origin
+}
+
+// recordOrigins record origin for every leaf key of data
+func (c *Config) recordOrigins(data map[string]interface{}, prefix, origin string) {
+	for name, val := range data {
+		key := joinKey(prefix, name)
+		if nested, ok := val.(map[string]interface{}); ok && len(nested) > 0 {
+			c.recordOrigins(nested, key, origin)
+			continue
+		}
+		c.recordOrigin(key, origin)
+	}
+}
@@ -0,0 +1,317 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// overrideMode define how override values are interpreted
+type overrideMode int
+
+const (
+	overrideTyped overrideMode = iota
+	overrideString
+	overrideFile
+)
+
+// maxOverrideIndex bound list indexes, so "servers[2000000000]=x" cannot
+// allocate billions of elements (same limit as Helm)
+const maxOverrideIndex = 65536
+
+// overrideStep is one step of an override path: a map key or a list index
+type overrideStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// ParseOverrides parse Helm-style "key=value" overrides (like --set) into a config layer.
+// Values are inferred as null, bool, int, float, list ({a,b}) or string.
+func ParseOverrides(values []string) (*Config, error) {
+	return parseOverrides(values, overrideTyped)
+}
+
+// ParseStringOverrides parse overrides keeping every value as string (like --set-string)
+func ParseStringOverrides(values []string) (*Config, error) {
+	return parseOverrides(values, overrideString)
+}
+
+// ParseFileOverrides parse overrides where each value is a file path whose content
+// is used as string value (like --set-file)
+func ParseFileOverrides(values []string) (*Config, error) {
+	return parseOverrides(values, overrideFile)
+}
+
+func parseOverrides(values []string, mode overrideMode) (*Config, error) {
+	cfg := NewConfig()
+	if err := applyOverrides(cfg.Data, values, mode, nil); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyOverrides set every override directly into data, so list indexes
+// update existing lists instead of replacing them. When rename is set,
+// keys are rewritten through it first (aliases of the builder config).
+func applyOverrides(data map[string]interface{}, values []string, mode overrideMode, rename func(string) string) error {
+	for _, value := range values {
+		for _, assignment := range splitUnescaped(value, ',') {
+			if assignment == "" {
+				continue
+			}
+			if err := applyOverride(data, assignment, mode, rename); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func applyOverride(data map[string]interface{}, assignment string, mode overrideMode, rename func(string) string) error {
+	pos := indexUnescaped(assignment, '=')
+	if pos < 0 {
+		return fmt.Errorf("invalid override %q: expected key=value", assignment)
+	}
+
+	steps, err := parseOverrideKey(assignment[:pos])
+	if err != nil {
+		return fmt.Errorf("invalid override %q: %w", assignment, err)
+	}
+	if rename != nil {
+		steps = renameOverrideSteps(steps, rename)
+	}
+
+	raw := assignment[pos+1:]
+	var value interface{}
+	switch mode {
+	case overrideString:
+		value = unescapeOverride(raw)
+	case overrideFile:
+		content, err := os.ReadFile(unescapeOverride(raw))
+		if err != nil {
+			return fmt.Errorf("invalid override %q: %w", assignment, err)
+		}
+		value = string(content)
+	default:
+		value = inferOverrideValue(raw)
+	}
+
+	_, err = setOverridePath(data, steps, value)
+	if err != nil {
+		return fmt.Errorf("invalid override %q: %w", assignment, err)
+	}
+	return nil
+}
+
+// parseOverrideKey split "servers[0].host" into steps, supporting "\." escapes
+func parseOverrideKey(key string) ([]overrideStep, error) {
+	var steps []overrideStep
+	var current strings.Builder
+	pendingKey := false
+
+	flush := func() error {
+		if current.Len() == 0 {
+			if pendingKey {
+				return fmt.Errorf("empty key segment")
+			}
+			return nil
+		}
+		steps = append(steps, overrideStep{key: current.String()})
+		current.Reset()
+		pendingKey = false
+		return nil
+	}
+
+	pendingKey = true
+	for i := 0; i < len(key); i++ {
+		ch := key[i]
+		switch {
+		case ch == '\\' && i+1 < len(key):
+			i++
+			current.WriteByte(key[i])
+		case ch == '.':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			pendingKey = true
+		case ch == '[':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			end := strings.IndexByte(key[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed index in key %q", key)
+			}
+			index, err := strconv.Atoi(key[i+1 : i+end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in key %q", key[i+1:i+end], key)
+			}
+			if index > maxOverrideIndex {
+				return nil, fmt.Errorf("index %d in key %q exceeds the maximum of %d", index, key, maxOverrideIndex)
+			}
+			steps = append(steps, overrideStep{index: index, isIndex: true})
+			i += end
+			if i+1 < len(key) && key[i+1] != '.' && key[i+1] != '[' {
+				return nil, fmt.Errorf("unexpected %q after index in key %q", key[i+1], key)
+			}
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	if len(steps) == 0 || steps[0].isIndex {
+		return nil, fmt.Errorf("key must start with a name")
+	}
+	return steps, nil
+}
+
+// renameOverrideSteps rewrite the leading map keys of steps (the part
+// before the first index) through rename
+func renameOverrideSteps(steps []overrideStep, rename func(string) string) []overrideStep {
+	n := 0
+	keys := make([]string, 0, len(steps))
+	for ; n < len(steps) && !steps[n].isIndex; n++ {
+		keys = append(keys, steps[n].key)
+	}
+	key := strings.Join(keys, ".")
+	renamed := rename(key)
+	if renamed == key {
+		return steps
+	}
+
+	var result []overrideStep
+	for _, part := range strings.Split(renamed, ".") {
+		result = append(result, overrideStep{key: part})
+	}
+	return append(result, steps[n:]...)
+}
+
+// setOverridePath set value at the path, creating maps and growing lists as needed
+func setOverridePath(current interface{}, steps []overrideStep, value interface{}) (interface{}, error) {
+	if len(steps) == 0 {
+		return value, nil
+	}
+
+	step := steps[0]
+	if step.isIndex {
+		list, _ := current.([]interface{})
+		for len(list) <= step.index {
+			list = append(list, nil)
+		}
+		val, err := setOverridePath(list[step.index], steps[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		list[step.index] = val
+		return list, nil
+	}
+
+	m, ok := current.(map[string]interface{})
+	if !ok {
+		m = make(map[string]interface{})
+	}
+	val, err := setOverridePath(m[step.key], steps[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	m[step.key] = val
+	return m, nil
+}
+
+// inferOverrideValue convert a raw literal into its typed value
+func inferOverrideValue(raw string) interface{} {
+	if strings.HasPrefix(raw, "{") && strings.HasSuffix(raw, "}") {
+		inner := raw[1 : len(raw)-1]
+		list := make([]interface{}, 0)
+		if inner == "" {
+			return list
+		}
+		for _, item := range splitUnescaped(inner, ',') {
+			list = append(list, inferOverrideValue(item))
+		}
+		return list
+	}
+
+	switch raw {
+	case "null":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	// Keep values with leading zeros (like "007" or "-007") as strings
+	digits := raw
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		digits = digits[1:]
+	}
+	if !(len(digits) > 1 && digits[0] == '0' && digits[1] != '.') {
+		if i, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return i
+		}
+		if f, err := strconv.ParseFloat(raw, 64); err == nil && !strings.ContainsAny(raw, "xXnN") {
+			return f
+		}
+	}
+	return unescapeOverride(raw)
+}
+
+// splitUnescaped split s by sep ignoring escaped separators and those inside braces
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	depth := 0
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// indexUnescaped return the position of the first unescaped sep
+func indexUnescaped(s string, sep byte) int {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == sep {
+			return i
+		}
+	}
+	return -1
+}
+
+// unescapeOverride remove escape backslashes from a value
+func unescapeOverride(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
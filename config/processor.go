@@ -0,0 +1,78 @@
+package config
+
+import "fmt"
+
+// Processor transform the merged config tree after every source is loaded
+// (e.g. resolving secret references or decrypting values)
+type Processor interface {
+	Process(data map[string]interface{}) error
+}
+
+// ProcessorFunc adapt a function to the Processor interface
+type ProcessorFunc func(data map[string]interface{}) error
+
+// Process call the function
+func (f ProcessorFunc) Process(data map[string]interface{}) error {
+	return f(data)
+}
+
+// PollingProcessor is a Processor whose output can change over time
+// (e.g. expiring secrets); the watcher polls it on every tick
+type PollingProcessor interface {
+	Processor
+	Changed() (bool, error)
+}
+
+// keyedProcessor is a Processor whose output depends on the config key of
+// each value (like decryption); it is also given the old keys a value may
+// have been loaded under before an alias moved it
+type keyedProcessor interface {
+	processKeys(data map[string]interface{}, aliasesOf func(key string) []string) error
+}
+
+// process run p on the data of cfg
+func process(p Processor, cfg *Config) error {
+	if keyed, ok := p.(keyedProcessor); ok {
+		return keyed.processKeys(cfg.Data, cfg.aliasesOf)
+	}
+	return p.Process(cfg.Data)
+}
+
+// walkStrings call fn for every string value in the tree with its dot-notation
+// key, replacing the value with the result
+func walkStrings(data map[string]interface{}, prefix string, fn func(key, value string) (interface{}, error)) []error {
+	var errs []error
+	for key, val := range data {
+		fullKey := key
+		if prefix != "" {
+			fullKey = prefix + "." + key
+		}
+		newVal, valErrs := walkValue(val, fullKey, fn)
+		data[key] = newVal
+		errs = append(errs, valErrs...)
+	}
+	return errs
+}
+
+func walkValue(val interface{}, key string, fn func(key, value string) (interface{}, error)) (interface{}, []error) {
+	switch v := val.(type) {
+	case string:
+		result, err := fn(key, v)
+		if err != nil {
+			return v, []error{err}
+		}
+		return result, nil
+	case map[string]interface{}:
+		return v, walkStrings(v, key, fn)
+	case []interface{}:
+		var errs []error
+		for i, item := range v {
+			newItem, itemErrs := walkValue(item, fmt.Sprintf("%s[%d]", key, i), fn)
+			v[i] = newItem
+			errs = append(errs, itemErrs...)
+		}
+		return v, errs
+	default:
+		return v, nil
+	}
+}
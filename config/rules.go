@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+)
+
+// RuleSet hold cross-key validation rules evaluated on the merged config:
+//
+//	rules := config.NewRuleSet()
+//	rules.When("tls.enabled", true).Required("tls.cert", "tls.key")
+//	rules.LessOrEqual("pool.min", "pool.max")
+type RuleSet struct {
+	condition string
+	checks    []func(c *Config) []error
+}
+
+// NewRuleSet create an empty rule set
+func NewRuleSet() *RuleSet {
+	return &RuleSet{}
+}
+
+// Required require keys to be present
+func (r *RuleSet) Required(keys ...string) *RuleSet {
+	r.checks = append(r.checks, func(c *Config) []error {
+		var errs []error
+		for _, key := range keys {
+			if _, err := c.GetValue(key); err != nil {
+				if r.condition == "" {
+					errs = append(errs, err)
+					continue
+				}
+				errs = append(errs, &ValidationError{Key: key, Message: "required when " + r.condition})
+			}
+		}
+		return errs
+	})
+	return r
+}
+
+// When return a nested rule set evaluated only when key equals value
+func (r *RuleSet) When(key string, value interface{}) *RuleSet {
+	expected, _ := ConvertToString(value)
+	return r.WhenFunc(fmt.Sprintf("%s is %s", key, expected), func(c *Config) bool {
+		actual, err := c.GetValue(key)
+		if err != nil {
+			return false
+		}
+		str, _ := ConvertToString(actual)
+		return str == expected
+	})
+}
+
+// WhenPresent return a nested rule set evaluated only when key is present
+func (r *RuleSet) WhenPresent(key string) *RuleSet {
+	return r.WhenFunc(key+" is set", func(c *Config) bool {
+		_, err := c.GetValue(key)
+		return err == nil
+	})
+}
+
+// WhenFunc return a nested rule set evaluated only when condition holds;
+// description is used in error messages ("required when <description>")
+func (r *RuleSet) WhenFunc(description string, condition func(c *Config) bool) *RuleSet {
+	nested := &RuleSet{condition: description}
+	r.checks = append(r.checks, func(c *Config) []error {
+		if !condition(c) {
+			return nil
+		}
+		return nested.validate(c)
+	})
+	return nested
+}
+
+// LessOrEqual require the numeric value of a to be <= b when both are present
+func (r *RuleSet) LessOrEqual(a, b string) *RuleSet {
+	return r.compare(a, b, "<=", func(x, y float64) bool { return x <= y })
+}
+
+// LessThan require the numeric value of a to be < b when both are present
+func (r *RuleSet) LessThan(a, b string) *RuleSet {
+	return r.compare(a, b, "<", func(x, y float64) bool { return x < y })
+}
+
+// Check register a predicate over the values of keys, evaluated only when
+// every key is present; message describes the violation
+func (r *RuleSet) Check(name string, keys []string, predicate func(values ...interface{}) bool, message string) *RuleSet {
+	r.checks = append(r.checks, func(c *Config) []error {
+		values := make([]interface{}, len(keys))
+		for i, key := range keys {
+			val, err := c.GetValue(key)
+			if err != nil {
+				return nil
+			}
+			values[i] = val
+		}
+		if !predicate(values...) {
+			return []error{&RuleError{Rule: name, Keys: keys, Message: message}}
+		}
+		return nil
+	})
+	return r
+}
+
+// ValidateConfig evaluate every rule, returning a MultiError with all the violations
+func (r *RuleSet) ValidateConfig(c *Config) error {
+	if errs := r.validate(c); len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+func (r *RuleSet) validate(c *Config) []error {
+	var errs []error
+	for _, check := range r.checks {
+		errs = append(errs, check(c)...)
+	}
+	return errs
+}
+
+func (r *RuleSet) compare(a, b, op string, cmp func(x, y float64) bool) *RuleSet {
+	r.checks = append(r.checks, func(c *Config) []error {
+		valA, errA := c.GetValue(a)
+		valB, errB := c.GetValue(b)
+		if errA != nil || errB != nil {
+			return nil // Presence is checked by Required or the schema
+		}
+
+		x, okA := toFloat(valA)
+		y, okB := toFloat(valB)
+		var errs []error
+		if !okA {
+			errs = append(errs, &TypeError{Key: a, Expected: "number", Actual: fmt.Sprintf("%T", valA)})
+		}
+		if !okB {
+			errs = append(errs, &TypeError{Key: b, Expected: "number", Actual: fmt.Sprintf("%T", valB)})
+		}
+		if len(errs) > 0 {
+			return errs
+		}
+
+		if !cmp(x, y) {
+			return []error{&RuleError{
+				Rule:    a + " " + op + " " + b,
+				Keys:    []string{a, b},
+				Message: fmt.Sprintf("%v is not %s %v", x, op, y),
+			}}
+		}
+		return nil
+	})
+	return r
+}
@@ -0,0 +1,370 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType is the expected type of a schema field
+type FieldType string
+
+const (
+	TypeAny      FieldType = "any"
+	TypeString   FieldType = "string"
+	TypeInt      FieldType = "int"
+	TypeFloat    FieldType = "float"
+	TypeBool     FieldType = "bool"
+	TypeDuration FieldType = "duration"
+	TypeList     FieldType = "list"
+	TypeMap      FieldType = "map"
+)
+
+// Format is a well-known string format checked by a schema field
+type Format string
+
+const (
+	FormatURL      Format = "url"
+	FormatHostname Format = "hostname"
+	FormatPort     Format = "port"
+	FormatDuration Format = "duration"
+)
+
+var hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// Schema declare the expected keys of a config and their constraints
+type Schema struct {
+	fields []*FieldSchema
+	index  map[string]*FieldSchema
+}
+
+// NewSchema create an empty schema
+func NewSchema() *Schema {
+	return &Schema{index: make(map[string]*FieldSchema)}
+}
+
+// Field declare (or return the already declared) field for key
+func (s *Schema) Field(key string, fieldType FieldType) *FieldSchema {
+	if field, exists := s.index[key]; exists {
+		field.fieldType = fieldType
+		return field
+	}
+	field := &FieldSchema{key: key, fieldType: fieldType}
+	s.fields = append(s.fields, field)
+	s.index[key] = field
+	return field
+}
+
+// String declare a string field
+func (s *Schema) String(key string) *FieldSchema { return s.Field(key, TypeString) }
+
+// Int declare an integer field
+func (s *Schema) Int(key string) *FieldSchema { return s.Field(key, TypeInt) }
+
+// Float declare a numeric field
+func (s *Schema) Float(key string) *FieldSchema { return s.Field(key, TypeFloat) }
+
+// Bool declare a boolean field
+func (s *Schema) Bool(key string) *FieldSchema { return s.Field(key, TypeBool) }
+
+// Duration declare a duration field ("5s", "1m30s")
+func (s *Schema) Duration(key string) *FieldSchema { return s.Field(key, TypeDuration) }
+
+// List declare a list field
+func (s *Schema) List(key string) *FieldSchema { return s.Field(key, TypeList) }
+
+// Map declare a nested object field
+func (s *Schema) Map(key string) *FieldSchema { return s.Field(key, TypeMap) }
+
+// Keys return the declared keys in declaration order
+func (s *Schema) Keys() []string {
+	keys := make([]string, len(s.fields))
+	for i, field := range s.fields {
+		keys[i] = field.key
+	}
+	return keys
+}
+
+// ApplyDefaults set the default of every missing field that declares one
+func (s *Schema) ApplyDefaults(c *Config) {
+	for _, field := range s.fields {
+		if !field.hasDefault {
+			continue
+		}
+		if _, err := c.GetValue(field.key); err != nil {
+			c.Set(field.key, field.defaultValue)
+		}
+	}
+}
+
+// ValidateConfig validate c against the schema
+func (s *Schema) ValidateConfig(c *Config) error {
+	return c.ValidateSchema(s)
+}
+
+// FieldSchema hold the constraints of a single key
+type FieldSchema struct {
+	key          string
+	fieldType    FieldType
+	required     bool
+	hasDefault   bool
+	defaultValue interface{}
+	min, max     *float64
+	minLen       *int
+	maxLen       *int
+	enum         []interface{}
+	pattern      *regexp.Regexp
+	patternErr   error
+	format       Format
+}
+
+// Required mark the field as mandatory
+func (f *FieldSchema) Required() *FieldSchema {
+	f.required = true
+	return f
+}
+
+// Default set the value applied by ApplyDefaults when the key is missing
+func (f *FieldSchema) Default(value interface{}) *FieldSchema {
+	f.hasDefault = true
+	f.defaultValue = value
+	return f
+}
+
+// Min set the minimum numeric value
+func (f *FieldSchema) Min(min float64) *FieldSchema {
+	f.min = &min
+	return f
+}
+
+// Max set the maximum numeric value
+func (f *FieldSchema) Max(max float64) *FieldSchema {
+	f.max = &max
+	return f
+}
+
+// MinLen set the minimum length of a string or list
+func (f *FieldSchema) MinLen(min int) *FieldSchema {
+	f.minLen = &min
+	return f
+}
+
+// MaxLen set the maximum length of a string or list
+func (f *FieldSchema) MaxLen(max int) *FieldSchema {
+	f.maxLen = &max
+	return f
+}
+
+// Enum restrict the field to a set of values
+func (f *FieldSchema) Enum(values ...interface{}) *FieldSchema {
+	f.enum = values
+	return f
+}
+
+// Pattern require string values to match a regular expression
+func (f *FieldSchema) Pattern(expr string) *FieldSchema {
+	f.pattern, f.patternErr = regexp.Compile(expr)
+	return f
+}
+
+// Format require string values to have a well-known format
+func (f *FieldSchema) Format(format Format) *FieldSchema {
+	f.format = format
+	return f
+}
+
+// validate check the field against the config, returning every violation
+func (f *FieldSchema) validate(c *Config) []error {
+	val, err := c.GetValue(f.key)
+	if err != nil {
+		if _, missing := err.(*KeyError); missing && !f.required {
+			return nil
+		}
+		return []error{err}
+	}
+
+	typed, err := f.checkType(val)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Key: f.key, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if number, ok := typed.(float64); ok {
+		if f.min != nil && number < *f.min {
+			invalid("must be >= %v", *f.min)
+		}
+		if f.max != nil && number > *f.max {
+			invalid("must be <= %v", *f.max)
+		}
+	}
+
+	if length, ok := valueLength(typed); ok {
+		if f.minLen != nil && length < *f.minLen {
+			invalid("length must be >= %d", *f.minLen)
+		}
+		if f.maxLen != nil && length > *f.maxLen {
+			invalid("length must be <= %d", *f.maxLen)
+		}
+	}
+
+	if len(f.enum) > 0 && !inEnum(val, f.enum) {
+		invalid("must be one of %v", f.enum)
+	}
+
+	if f.patternErr != nil {
+		invalid("invalid pattern: %v", f.patternErr)
+	} else if f.pattern != nil {
+		str, _ := ConvertToString(val)
+		if !f.pattern.MatchString(str) {
+			invalid("must match pattern %s", f.pattern)
+		}
+	}
+
+	if f.format != "" {
+		if msg := checkFormat(f.format, val); msg != "" {
+			invalid("%s", msg)
+		}
+	}
+	return errs
+}
+
+// checkType verify the value type, returning it normalized (numbers as float64)
+func (f *FieldSchema) checkType(val interface{}) (interface{}, error) {
+	typeErr := &TypeError{Key: f.key, Expected: string(f.fieldType), Actual: fmt.Sprintf("%T", val)}
+
+	switch f.fieldType {
+	case TypeString:
+		str, ok := val.(string)
+		if !ok {
+			return nil, typeErr
+		}
+		return str, nil
+	case TypeInt:
+		number, ok := toFloat(val)
+		if !ok || number != math.Trunc(number) {
+			return nil, typeErr
+		}
+		return number, nil
+	case TypeFloat:
+		number, ok := toFloat(val)
+		if !ok {
+			return nil, typeErr
+		}
+		return number, nil
+	case TypeBool:
+		if _, err := ConvertToBool(val, f.key); err != nil {
+			return nil, typeErr
+		}
+		return val, nil
+	case TypeDuration:
+		str, ok := val.(string)
+		if !ok {
+			return nil, typeErr
+		}
+		if _, err := time.ParseDuration(str); err != nil {
+			return nil, typeErr
+		}
+		return val, nil
+	case TypeList:
+		if _, ok := val.([]interface{}); !ok {
+			return nil, typeErr
+		}
+		return val, nil
+	case TypeMap:
+		if _, ok := val.(map[string]interface{}); !ok {
+			return nil, typeErr
+		}
+		return val, nil
+	default:
+		if number, ok := toFloat(val); ok {
+			if _, isString := val.(string); !isString {
+				return number, nil
+			}
+		}
+		return val, nil
+	}
+}
+
+// toFloat convert numbers and numeric strings to float64. NaN and
+// infinities are rejected: every comparison with NaN is false, so it
+// would pass any range check.
+func toFloat(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil && isFinite(number)
+	case bool, nil:
+		return 0, false
+	case float32:
+		return float64(v), isFinite(float64(v))
+	case float64:
+		return v, isFinite(v)
+	}
+	if number, err := ConvertToInt(val, ""); err == nil {
+		return float64(number), true
+	}
+	return 0, false
+}
+
+func isFinite(number float64) bool {
+	return !math.IsNaN(number) && !math.IsInf(number, 0)
+}
+
+func valueLength(val interface{}) (int, bool) {
+	switch v := val.(type) {
+	case string:
+		return len([]rune(v)), true
+	case []interface{}:
+		return len(v), true
+	case map[string]interface{}:
+		return len(v), true
+	}
+	return 0, false
+}
+
+func inEnum(val interface{}, enum []interface{}) bool {
+	str, _ := ConvertToString(val)
+	for _, allowed := range enum {
+		allowedStr, _ := ConvertToString(allowed)
+		if str == allowedStr {
+			return true
+		}
+	}
+	return false
+}
+
+// checkFormat return a violation message, or "" when val has the format
+func checkFormat(format Format, val interface{}) string {
+	str, _ := ConvertToString(val)
+	switch format {
+	case FormatURL:
+		u, err := url.Parse(str)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return "must be a valid URL"
+		}
+	case FormatHostname:
+		if len(str) > 253 || !hostnamePattern.MatchString(str) {
+			return "must be a valid hostname"
+		}
+	case FormatPort:
+		port, err := strconv.Atoi(str)
+		if err != nil || port < 1 || port > 65535 {
+			return "must be a valid port (1-65535)"
+		}
+	case FormatDuration:
+		if _, err := time.ParseDuration(str); err != nil {
+			return "must be a valid duration"
+		}
+	default:
+		return fmt.Sprintf("unknown format %q", format)
+	}
+	return ""
+}
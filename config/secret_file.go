@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// FileSecretResolver resolve secrets from a local JSON file, useful for
+// local development and tests. The file maps secret paths to either a
+// value or an object of fields:
+//
+//	{"db/main": {"username": "app", "password": "s3cr3t"}, "api/token": "abc"}
+type FileSecretResolver struct {
+	path string
+	ttl  time.Duration
+}
+
+// NewFileSecretResolver create a resolver reading path on each resolve
+func NewFileSecretResolver(path string) *FileSecretResolver {
+	return &FileSecretResolver{path: path}
+}
+
+// WithTTL set the TTL of resolved secrets so changes to the file are picked up
+func (r *FileSecretResolver) WithTTL(ttl time.Duration) *FileSecretResolver {
+	r.ttl = ttl
+	return r
+}
+
+// Resolve read the secret from the file
+func (r *FileSecretResolver) Resolve(ref SecretRef) (Secret, error) {
+	content, err := os.ReadFile(r.path)
+	if err != nil {
+		return Secret{}, fmt.Errorf("error opening secrets file: %w", err)
+	}
+
+	var secrets map[string]interface{}
+	if err := json.Unmarshal(content, &secrets); err != nil {
+		return Secret{}, fmt.Errorf("error decoding secrets file: %w", err)
+	}
+
+	entry, exists := secrets[ref.Path]
+	if !exists {
+		return Secret{}, &KeyError{Key: ref.Path}
+	}
+
+	if ref.Field != "" {
+		fields, ok := entry.(map[string]interface{})
+		if !ok {
+			return Secret{}, &PathError{Key: ref.String(), Segment: ref.Path}
+		}
+		if entry, exists = fields[ref.Field]; !exists {
+			return Secret{}, &KeyError{Key: ref.Path + "#" + ref.Field}
+		}
+	}
+
+	value, err := ConvertToString(entry)
+	if err != nil {
+		return Secret{}, err
+	}
+	return Secret{Value: value, TTL: r.ttl}, nil
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// secretScheme is the prefix of secret reference values
+const secretScheme = "secret://"
+
+// SecretRef point to a secret, written in config as "secret://<path>#<field>"
+type SecretRef struct {
+	Path  string
+	Field string
+}
+
+// String return the reference in its config form
+func (r SecretRef) String() string {
+	if r.Field == "" {
+		return secretScheme + r.Path
+	}
+	return secretScheme + r.Path + "#" + r.Field
+}
+
+// ParseSecretRef parse a "secret://<path>#<field>" value
+func ParseSecretRef(value string) (SecretRef, bool) {
+	if !strings.HasPrefix(value, secretScheme) {
+		return SecretRef{}, false
+	}
+	ref := strings.TrimPrefix(value, secretScheme)
+	path, field, _ := strings.Cut(ref, "#")
+	if path == "" {
+		return SecretRef{}, false
+	}
+	return SecretRef{Path: path, Field: field}, true
+}
+
+// Secret is a resolved secret value. A zero TTL means it never expires.
+type Secret struct {
+	Value     string
+	TTL       time.Duration
+	LeaseID   string
+	Renewable bool
+}
+
+// SecretResolver resolve secret references (e.g. from Vault or a cloud secret manager)
+type SecretResolver interface {
+	Resolve(ref SecretRef) (Secret, error)
+}
+
+// LeaseRenewer is implemented by resolvers whose leased secrets can be renewed
+type LeaseRenewer interface {
+	Renew(ref SecretRef, secret Secret) (Secret, error)
+}
+
+// SecretError report a secret that could not be resolved for a key
+type SecretError struct {
+	Key string
+	Ref string
+	Err error
+}
+
+func (e *SecretError) Error() string {
+	return fmt.Sprintf("error resolving secret '%s' for key '%s': %v", e.Ref, e.Key, e.Err)
+}
+
+func (e *SecretError) Unwrap() error {
+	return e.Err
+}
+
+// renewAfter is the fraction of a lease TTL after which it is renewed, so
+// the renewal happens while the lease is still alive
+const renewAfter = 2.0 / 3.0
+
+// cachedSecret is a resolved secret and its expiration
+type cachedSecret struct {
+	secret    Secret
+	expires   time.Time
+	renewAt   time.Time
+	processed bool // Resolved for a config by Process
+}
+
+// SecretManager resolve secret references in config values through a
+// SecretResolver, caching results until their TTL expires
+type SecretManager struct {
+	resolver  SecretResolver
+	mu        sync.Mutex
+	cache     map[SecretRef]cachedSecret
+	callbacks []func(SecretRef, Secret)
+}
+
+// NewSecretManager create a secret manager for resolver
+func NewSecretManager(resolver SecretResolver) *SecretManager {
+	return &SecretManager{
+		resolver: resolver,
+		cache:    make(map[SecretRef]cachedSecret),
+	}
+}
+
+// OnLeaseRenew register callback invoked after a lease is renewed
+func (m *SecretManager) OnLeaseRenew(callback func(SecretRef, Secret)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callbacks = append(m.callbacks, callback)
+}
+
+// Resolve return the secret for ref, using the cache while it is valid
+// and renewing leases that are due
+func (m *SecretManager) Resolve(ref SecretRef) (string, error) {
+	return m.resolve(ref, false)
+}
+
+func (m *SecretManager) resolve(ref SecretRef, processed bool) (string, error) {
+	m.mu.Lock()
+	if cached, ok := m.cache[ref]; ok && !cached.expired() && !cached.renewDue() {
+		if processed && !cached.processed {
+			cached.processed = true
+			m.cache[ref] = cached
+		}
+		m.mu.Unlock()
+		return cached.secret.Value, nil
+	}
+	secret, renewed, err := m.refresh(ref)
+	if err == nil && processed {
+		cached := m.cache[ref]
+		cached.processed = true
+		m.cache[ref] = cached
+	}
+	callbacks := m.callbacks
+	m.mu.Unlock()
+
+	if err != nil {
+		return "", err
+	}
+	if renewed {
+		notifyRenewed(callbacks, ref, secret)
+	}
+	return secret.Value, nil
+}
+
+// Process replace every "secret://" value in the tree with its resolved
+// secret. Secrets resolved by an earlier Process call and no longer
+// referenced are dropped from the cache.
+func (m *SecretManager) Process(data map[string]interface{}) error {
+	seen := make(map[SecretRef]bool)
+	errs := walkStrings(data, "", func(key, value string) (interface{}, error) {
+		ref, ok := ParseSecretRef(value)
+		if !ok {
+			return value, nil
+		}
+		seen[ref] = true
+		resolved, err := m.resolve(ref, true)
+		if err != nil {
+			return value, &SecretError{Key: key, Ref: value, Err: err}
+		}
+		return resolved, nil
+	})
+
+	m.mu.Lock()
+	for ref, cached := range m.cache {
+		if cached.processed && !seen[ref] {
+			delete(m.cache, ref)
+		}
+	}
+	m.mu.Unlock()
+
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// Changed renew leases past 2/3 of their TTL, resolve expired secrets
+// again and report if any value changed, so the watcher can reload
+func (m *SecretManager) Changed() (bool, error) {
+	m.mu.Lock()
+	changed := false
+	var errs []error
+	renewed := make(map[SecretRef]Secret)
+	for ref, cached := range m.cache {
+		if !cached.expired() && !cached.renewDue() {
+			continue
+		}
+		secret, wasRenewed, err := m.refresh(ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if wasRenewed {
+			renewed[ref] = secret
+		}
+		changed = changed || secret.Value != cached.secret.Value
+	}
+	callbacks := m.callbacks
+	m.mu.Unlock()
+
+	for ref, secret := range renewed {
+		notifyRenewed(callbacks, ref, secret)
+	}
+	if len(errs) > 0 {
+		return changed, MultiError{Errors: errs}
+	}
+	return changed, nil
+}
+
+// Invalidate drop every cached secret
+func (m *SecretManager) Invalidate() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cache = make(map[SecretRef]cachedSecret)
+}
+
+// refresh renew the lease of a secret due for renewal, or resolve it again
+// when it expired
+func (m *SecretManager) refresh(ref SecretRef) (Secret, bool, error) {
+	if cached, ok := m.cache[ref]; ok && cached.secret.Renewable && !cached.expired() {
+		if renewer, ok := m.resolver.(LeaseRenewer); ok {
+			secret, err := renewer.Renew(ref, cached.secret)
+			if err == nil {
+				m.store(ref, secret)
+				return secret, true, nil
+			}
+			// Fall back to a fresh resolve when renewal fails
+		}
+	}
+
+	secret, err := m.resolver.Resolve(ref)
+	if err != nil {
+		return Secret{}, false, err
+	}
+	m.store(ref, secret)
+	return secret, false, nil
+}
+
+func notifyRenewed(callbacks []func(SecretRef, Secret), ref SecretRef, secret Secret) {
+	for _, callback := range callbacks {
+		callback(ref, secret)
+	}
+}
+
+func (m *SecretManager) store(ref SecretRef, secret Secret) {
+	cached := cachedSecret{secret: secret, processed: m.cache[ref].processed}
+	if secret.TTL > 0 {
+		now := time.Now()
+		cached.expires = now.Add(secret.TTL)
+		if secret.Renewable {
+			cached.renewAt = now.Add(time.Duration(float64(secret.TTL) * renewAfter))
+		}
+	}
+	m.cache[ref] = cached
+}
+
+func (c cachedSecret) expired() bool {
+	return !c.expires.IsZero() && !time.Now().Before(c.expires)
+}
+
+// renewDue report if a renewable lease reached its renewal time
+func (c cachedSecret) renewDue() bool {
+	return !c.renewAt.IsZero() && !time.Now().Before(c.renewAt)
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"encoding/json"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// RedactedValue replace sensitive values in dumps and logs
+const RedactedValue = "[REDACTED]"
+
+// DefaultSensitivePatterns are key patterns always treated as sensitive
+var DefaultSensitivePatterns = []string{
+	"*password*",
+	"*passwd*",
+	"*secret*",
+	"*token*",
+	"*apikey*",
+	"*api_key*",
+	"*private_key*",
+	"*credential*",
+}
+
+// MarkSensitive mark keys matching glob-style patterns (e.g. "db.*", "*cert*")
+// as sensitive. Getters still return real values; dumps mask them.
+func (c *Config) MarkSensitive(patterns ...string) {
+	for _, pattern := range patterns {
+		c.sensitive = append(c.sensitive, strings.ToLower(pattern))
+	}
+}
+
+// IsSensitive report if a dot-notation key matches a sensitive pattern
+func (c *Config) IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, patterns := range [][]string{DefaultSensitivePatterns, c.sensitive} {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, key); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RedactValue return RedactedValue when key is sensitive, or value with its
+// sensitive children masked otherwise
+func (c *Config) RedactValue(key string, value interface{}) interface{} {
+	if c.IsSensitive(key) {
+		return RedactedValue
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for childKey, childVal := range v {
+			fullKey := childKey
+			if key != "" {
+				fullKey = key + "." + childKey
+			}
+			redacted[childKey] = c.RedactValue(fullKey, childVal)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, item := range v {
+			redacted[i] = c.RedactValue(key, item)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// Redacted return a copy of the config data with sensitive values masked
+func (c *Config) Redacted() map[string]interface{} {
+	redacted, _ := c.RedactValue("", c.data()).(map[string]interface{})
+	return redacted
+}
+
+// Dump return the config as indented JSON with sensitive values masked
+func (c *Config) Dump() ([]byte, error) {
+	return json.MarshalIndent(c.Redacted(), "", "  ")
+}
+
+// String return the config as JSON with sensitive values masked
+func (c *Config) String() string {
+	data, err := json.Marshal(c.Redacted())
+	if err != nil {
+		return "<invalid config: " + err.Error() + ">"
+	}
+	return string(data)
+}
+
+// LogValue log the config with sensitive values masked; slog handlers
+// would otherwise encode the exported Data field as is
+func (c *Config) LogValue() slog.Value {
+	return slog.AnyValue(c.Redacted())
+}
@@ -0,0 +1,22 @@
+package config
+
+// Source provide config data from origins other than local JSON files
+type Source interface {
+	Load() (map[string]interface{}, error)
+}
+
+// PollingSource is a Source the watcher can poll for changes on each tick
+type PollingSource interface {
+	Source
+	Changed() (bool, error)
+}
+
+// LoadFromSource load a source into config
+func (c *Config) LoadFromSource(src Source) error {
+	data, err := src.Load()
+	if err != nil {
+		return err
+	}
+	c.Merge(&Config{Data: data})
+	return nil
+}
@@ -0,0 +1,254 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// StrictLevel choose how unknown keys are reported
+type StrictLevel int
+
+const (
+	// StrictWarn report unknown keys through the warning hook only
+	StrictWarn StrictLevel = iota
+	// StrictError fail validation on unknown keys
+	StrictError
+)
+
+// StrictChecker report config keys not declared by a schema or struct,
+// with the source that set them and a "did you mean" suggestion
+type StrictChecker struct {
+	level    StrictLevel
+	declared map[string]bool // lowercased key -> accepts any nested key
+	keys     []string
+	onWarn   func(err *UnknownKeyError)
+}
+
+// NewStrictChecker create a checker reporting unknown keys at level
+func NewStrictChecker(level StrictLevel) *StrictChecker {
+	return &StrictChecker{
+		level:    level,
+		declared: make(map[string]bool),
+	}
+}
+
+// WithKeys declare known keys; "open" keys accept any nested key
+func (s *StrictChecker) WithKeys(keys ...string) *StrictChecker {
+	for _, key := range keys {
+		s.declare(key, false)
+	}
+	return s
+}
+
+// WithOpenKeys declare keys whose nested keys are free-form (e.g. label maps)
+func (s *StrictChecker) WithOpenKeys(keys ...string) *StrictChecker {
+	for _, key := range keys {
+		s.declare(key, true)
+	}
+	return s
+}
+
+// WithSchema declare the schema keys; map and any fields without nested
+// fields accept any nested key
+func (s *StrictChecker) WithSchema(schema *Schema) *StrictChecker {
+	for _, field := range schema.fields {
+		open := field.fieldType == TypeMap || field.fieldType == TypeAny
+		if open && schema.hasChildren(field.key) {
+			open = false
+		}
+		s.declare(field.key, open)
+	}
+	return s
+}
+
+// WithStruct declare the keys Unmarshal would bind into v (a struct or
+// struct pointer); map and interface fields accept any nested key
+func (s *StrictChecker) WithStruct(v interface{}) *StrictChecker {
+	t := reflect.TypeOf(v)
+	if t != nil {
+		s.declareStruct(indirectType(t), "", map[reflect.Type]bool{})
+	}
+	return s
+}
+
+// OnWarning replace the hook called for each unknown key in StrictWarn
+// level (default: a warning on the config logger)
+func (s *StrictChecker) OnWarning(fn func(err *UnknownKeyError)) *StrictChecker {
+	s.onWarn = fn
+	return s
+}
+
+// UnknownKeys return every undeclared key of c, sorted
+func (s *StrictChecker) UnknownKeys(c *Config) []*UnknownKeyError {
+	var unknown []*UnknownKeyError
+	s.walk(c, c.data(), "", &unknown)
+	return unknown
+}
+
+// ValidateConfig report unknown keys; in StrictWarn level they are passed
+// to the warning hook and validation succeeds
+func (s *StrictChecker) ValidateConfig(c *Config) error {
+	unknown := s.UnknownKeys(c)
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	if s.level == StrictWarn {
+		for _, err := range unknown {
+			if s.onWarn == nil {
+				c.Logger().Warn("unknown config key",
+					"key", err.Key, "source", err.Source, "suggestion", err.Suggestion)
+				continue
+			}
+			s.onWarn(err)
+		}
+		return nil
+	}
+
+	errs := make([]error, len(unknown))
+	for i, err := range unknown {
+		errs[i] = err
+	}
+	return MultiError{Errors: errs}
+}
+
+func (s *StrictChecker) declare(key string, open bool) {
+	lower := strings.ToLower(key)
+	if _, exists := s.declared[lower]; !exists {
+		s.keys = append(s.keys, key)
+	}
+	s.declared[lower] = s.declared[lower] || open
+}
+
+// declareStruct declare the fields of t under prefix; path hold the struct
+// types being declared, so self-referencing types stop instead of looping
+func (s *StrictChecker) declareStruct(t reflect.Type, prefix string, path map[reflect.Type]bool) {
+	path[t] = true
+	defer delete(path, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, skip := fieldConfigName(field)
+		if skip {
+			continue
+		}
+
+		fieldType := indirectType(field.Type)
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if !path[fieldType] {
+				s.declareStruct(fieldType, prefix, path)
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		key := joinKey(prefix, name)
+		switch {
+		case fieldType == durationType:
+			s.declare(key, false)
+		case fieldType.Kind() == reflect.Struct && path[fieldType]:
+			s.declare(key, true) // Recursive type: nested keys have no fixed depth
+		case fieldType.Kind() == reflect.Struct:
+			s.declare(key, false)
+			s.declareStruct(fieldType, key, path)
+		default:
+			open := fieldType.Kind() == reflect.Map || fieldType.Kind() == reflect.Interface
+			s.declare(key, open)
+		}
+	}
+}
+
+// known report whether key is declared or is a parent of declared keys,
+// and whether its nested keys are free-form
+func (s *StrictChecker) known(key string) (bool, bool) {
+	lower := strings.ToLower(key)
+	if open, exists := s.declared[lower]; exists {
+		return true, open
+	}
+	for declared := range s.declared {
+		if strings.HasPrefix(declared, lower+".") {
+			return true, false
+		}
+	}
+	return false, false
+}
+
+func (s *StrictChecker) walk(c *Config, data map[string]interface{}, prefix string, unknown *[]*UnknownKeyError) {
+	names := make([]string, 0, len(data))
+	for name := range data {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		key := joinKey(prefix, name)
+		known, open := s.known(key)
+		if !known {
+			*unknown = append(*unknown, &UnknownKeyError{
+				Key:        key,
+				Source:     c.Origin(key),
+				Suggestion: s.suggest(key),
+			})
+			continue
+		}
+		if nested, ok := data[name].(map[string]interface{}); ok && !open {
+			s.walk(c, nested, key, unknown)
+		}
+	}
+}
+
+// suggest return the closest declared key, or "" when none is close enough
+func (s *StrictChecker) suggest(key string) string {
+	best, bestDistance := "", -1
+	lower := strings.ToLower(key)
+	for _, candidate := range s.keys {
+		distance := levenshtein(lower, strings.ToLower(candidate))
+		if bestDistance < 0 || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	// Allow about one edit per three characters of the key
+	if bestDistance < 0 || bestDistance > len(key)/3+1 {
+		return ""
+	}
+	return best
+}
+
+// levenshtein compute the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func (s *Schema) hasChildren(key string) bool {
+	for _, field := range s.fields {
+		if strings.HasPrefix(field.key, key+".") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ValidationRule check a decoded field value against the rule parameter
+// (the text after "=" in the tag), returning an error describing the violation
+type ValidationRule func(value interface{}, param string) error
+
+var (
+	rulesMu     sync.RWMutex
+	customRules = make(map[string]ValidationRule)
+)
+
+// RegisterValidationRule register a rule usable in `validate` struct tags.
+// Built-in rules: required, min, max, len, oneof, url, hostname, port, duration.
+func RegisterValidationRule(name string, rule ValidationRule) {
+	rulesMu.Lock()
+	defer rulesMu.Unlock()
+	customRules[name] = rule
+}
+
+// tagRule is a single rule of a `validate` tag
+type tagRule struct {
+	name  string
+	param string
+}
+
+// tagRules is the parsed `validate` tag of a field
+type tagRules []tagRule
+
+// parseRules parse `validate:"required,min=1,max=65535"`
+func parseRules(tag string) tagRules {
+	var rules tagRules
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, param, _ := strings.Cut(part, "=")
+		rules = append(rules, tagRule{name: name, param: param})
+	}
+	return rules
+}
+
+func (r tagRules) has(name string) bool {
+	for _, rule := range r {
+		if rule.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// check run every rule on a decoded field value
+func (r tagRules) check(value reflect.Value, key string) []error {
+	var errs []error
+	for _, rule := range r {
+		if msg := rule.check(value); msg != "" {
+			errs = append(errs, &ValidationError{Key: key, Message: msg})
+		}
+	}
+	return errs
+}
+
+// check return a violation message, or "" when the value satisfies the rule
+func (r tagRule) check(value reflect.Value) string {
+	switch r.name {
+	case "required":
+		return "" // Presence is checked while decoding
+	case "min", "max", "len":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid %s parameter %q", r.name, r.param)
+		}
+		return checkLimit(r.name, value, limit)
+	case "oneof":
+		str, _ := ConvertToString(indirectValue(value))
+		for _, allowed := range strings.Fields(r.param) {
+			if str == allowed {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of [%s]", r.param)
+	case "url", "hostname", "port", "duration":
+		return checkFormat(Format(r.name), indirectValue(value))
+	}
+
+	rulesMu.RLock()
+	rule, exists := customRules[r.name]
+	rulesMu.RUnlock()
+	if !exists {
+		return fmt.Sprintf("unknown validation rule %q", r.name)
+	}
+	if err := rule(indirectValue(value), r.param); err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// checkLimit compare numbers by value and strings, lists and maps by length
+func checkLimit(name string, value reflect.Value, limit float64) string {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return ""
+		}
+		value = value.Elem()
+	}
+
+	var actual float64
+	what := "value"
+	switch value.Kind() {
+	case reflect.String:
+		actual, what = float64(len([]rune(value.String()))), "length"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		actual, what = float64(value.Len()), "length"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		actual = float64(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		actual = float64(value.Uint())
+	case reflect.Float32, reflect.Float64:
+		actual = value.Float()
+	default:
+		return fmt.Sprintf("%s is not supported for %s", name, value.Kind())
+	}
+
+	switch {
+	case name == "min" && actual < limit:
+		return fmt.Sprintf("%s must be >= %v", what, limit)
+	case name == "max" && actual > limit:
+		return fmt.Sprintf("%s must be <= %v", what, limit)
+	case name == "len" && actual != limit:
+		return fmt.Sprintf("%s must be %v", what, limit)
+	}
+	return ""
+}
+
+func indirectValue(value reflect.Value) interface{} {
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	return value.Interface()
+}
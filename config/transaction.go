@@ -0,0 +1,85 @@
+package config
+
+import "fmt"
+
+// ReloadParticipant take part in transactional reloads. Prepare is called
+// with the candidate config on every participant; if all succeed the config
+// is swapped in and Commit is called on each, otherwise Rollback is called
+// on the participants already prepared and the old config stays live.
+type ReloadParticipant interface {
+	Prepare(next *Config) error
+	Commit(next *Config)
+	Rollback(next *Config)
+}
+
+// ReloadFuncs adapt functions to the ReloadParticipant interface; nil
+// functions are skipped
+type ReloadFuncs struct {
+	PrepareFunc  func(next *Config) error
+	CommitFunc   func(next *Config)
+	RollbackFunc func(next *Config)
+}
+
+// Prepare call PrepareFunc
+func (f ReloadFuncs) Prepare(next *Config) error {
+	if f.PrepareFunc == nil {
+		return nil
+	}
+	return f.PrepareFunc(next)
+}
+
+// Commit call CommitFunc
+func (f ReloadFuncs) Commit(next *Config) {
+	if f.CommitFunc != nil {
+		f.CommitFunc(next)
+	}
+}
+
+// Rollback call RollbackFunc
+func (f ReloadFuncs) Rollback(next *Config) {
+	if f.RollbackFunc != nil {
+		f.RollbackFunc(next)
+	}
+}
+
+// AddParticipant register a participant of every reload
+func (w *ConfigWatcher) AddParticipant(p ReloadParticipant) {
+	w.participants = append(w.participants, p)
+}
+
+// prepare run the first phase; on a veto the prepared participants are
+// rolled back in reverse order
+func (w *ConfigWatcher) prepare(next *Config) error {
+	for i, p := range w.participants {
+		// A panic is a veto too
+		if err := safeCall(participantName(i, "Prepare"), func() error { return p.Prepare(next) }); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				w.participantCall(j, "Rollback", next)
+			}
+			return fmt.Errorf("reload vetoed: %w", err)
+		}
+	}
+	return nil
+}
+
+func (w *ConfigWatcher) commit(next *Config) {
+	for i := range w.participants {
+		w.participantCall(i, "Commit", next)
+	}
+}
+
+// participantCall run Commit or Rollback of participant i, reporting panics
+func (w *ConfigWatcher) participantCall(i int, phase string, next *Config) {
+	p := w.participants[i]
+	fn := p.Commit
+	if phase == "Rollback" {
+		fn = p.Rollback
+	}
+	if err := safeCall(participantName(i, phase), func() error { fn(next); return nil }); err != nil {
+		w.report(err)
+	}
+}
+
+func participantName(i int, phase string) string {
+	return fmt.Sprintf("participant #%d %s", i+1, phase)
+}
@@ -0,0 +1,266 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Unmarshal bind the whole config into a struct pointer. Fields are matched
+// by their `config` tag, then their `json` tag, then case-insensitively by
+// name, and checked against their `validate` tag. Every problem is reported
+// in a MultiError naming the config key.
+func (c *Config) Unmarshal(out interface{}) error {
+	return c.UnmarshalKey("", out)
+}
+
+// UnmarshalKey bind the subtree at key into a struct pointer
+func (c *Config) UnmarshalKey(key string, out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer, got %T", out)
+	}
+
+	var data interface{} = c.data()
+	if key != "" {
+		val, err := c.GetValue(key)
+		if err != nil {
+			return err
+		}
+		data = val
+	}
+
+	var errs []error
+	decodeValue(data, rv.Elem(), key, &errs)
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// decodeValue set out from a config value, appending errors keyed by config key
+func decodeValue(val interface{}, out reflect.Value, key string, errs *[]error) bool {
+	typeErr := func(expected string) bool {
+		*errs = append(*errs, &TypeError{Key: displayKey(key), Expected: expected, Actual: fmt.Sprintf("%T", val)})
+		return false
+	}
+
+	if out.Type() == durationType {
+		switch v := val.(type) {
+		case string:
+			d, err := time.ParseDuration(strings.TrimSpace(v))
+			if err != nil {
+				return typeErr("duration")
+			}
+			out.SetInt(int64(d))
+			return true
+		default:
+			n, err := ConvertToInt(val, key)
+			if err != nil {
+				return typeErr("duration")
+			}
+			out.SetInt(int64(n))
+			return true
+		}
+	}
+
+	switch out.Kind() {
+	case reflect.Ptr:
+		if val == nil {
+			out.Set(reflect.Zero(out.Type()))
+			return true
+		}
+		elem := reflect.New(out.Type().Elem())
+		if !decodeValue(val, elem.Elem(), key, errs) {
+			return false
+		}
+		out.Set(elem)
+		return true
+
+	case reflect.Interface:
+		if val == nil {
+			return true
+		}
+		if !reflect.TypeOf(val).AssignableTo(out.Type()) {
+			return typeErr(out.Type().String())
+		}
+		out.Set(reflect.ValueOf(val))
+		return true
+
+	case reflect.Struct:
+		m, ok := val.(map[string]interface{})
+		if !ok {
+			return typeErr("object")
+		}
+		return decodeStruct(m, out, key, errs)
+
+	case reflect.Map:
+		m, ok := val.(map[string]interface{})
+		if !ok || out.Type().Key().Kind() != reflect.String {
+			return typeErr("object")
+		}
+		result := reflect.MakeMapWithSize(out.Type(), len(m))
+		valid := true
+		for name, item := range m {
+			elem := reflect.New(out.Type().Elem()).Elem()
+			if decodeValue(item, elem, joinKey(key, name), errs) {
+				result.SetMapIndex(reflect.ValueOf(name).Convert(out.Type().Key()), elem)
+			} else {
+				valid = false
+			}
+		}
+		out.Set(result)
+		return valid
+
+	case reflect.Slice:
+		list, ok := val.([]interface{})
+		if !ok {
+			// Comma-separated strings (e.g. from env vars) fill string slices
+			str, isString := val.(string)
+			if !isString || out.Type().Elem().Kind() != reflect.String {
+				return typeErr("list")
+			}
+			list = nil
+			for _, item := range strings.Split(str, ",") {
+				list = append(list, strings.TrimSpace(item))
+			}
+		}
+		result := reflect.MakeSlice(out.Type(), len(list), len(list))
+		valid := true
+		for i, item := range list {
+			valid = decodeValue(item, result.Index(i), fmt.Sprintf("%s[%d]", key, i), errs) && valid
+		}
+		out.Set(result)
+		return valid
+
+	case reflect.String:
+		str, _ := ConvertToString(val)
+		out.SetString(str)
+		return true
+
+	case reflect.Bool:
+		b, err := ConvertToBool(val, key)
+		if err != nil {
+			return typeErr("bool")
+		}
+		out.SetBool(b)
+		return true
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := ConvertToInt(val, key)
+		if err != nil || out.OverflowInt(int64(n)) {
+			return typeErr(out.Kind().String())
+		}
+		out.SetInt(int64(n))
+		return true
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := ConvertToInt(val, key)
+		if err != nil || n < 0 || out.OverflowUint(uint64(n)) {
+			return typeErr(out.Kind().String())
+		}
+		out.SetUint(uint64(n))
+		return true
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat(val)
+		if !ok || out.OverflowFloat(f) {
+			return typeErr(out.Kind().String())
+		}
+		out.SetFloat(f)
+		return true
+	}
+
+	return typeErr(out.Type().String())
+}
+
+// decodeStruct fill the exported fields of out from m
+func decodeStruct(m map[string]interface{}, out reflect.Value, key string, errs *[]error) bool {
+	valid := true
+	outType := out.Type()
+
+	for i := 0; i < outType.NumField(); i++ {
+		field := outType.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, skip := fieldConfigName(field)
+		if skip {
+			continue
+		}
+
+		// Embedded structs without a name read from the same level
+		if field.Anonymous && name == "" && indirectType(field.Type).Kind() == reflect.Struct {
+			valid = decodeValue(m, out.Field(i), key, errs) && valid
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+
+		rules := parseRules(field.Tag.Get("validate"))
+		matched, raw, exists := lookupField(m, name)
+		if !exists {
+			if rules.has("required") {
+				*errs = append(*errs, &KeyError{Key: joinKey(key, name)})
+				valid = false
+			}
+			continue
+		}
+
+		fieldKey := joinKey(key, matched)
+
+		if !decodeValue(raw, out.Field(i), fieldKey, errs) {
+			valid = false
+			continue
+		}
+		if ruleErrs := rules.check(out.Field(i), fieldKey); len(ruleErrs) > 0 {
+			*errs = append(*errs, ruleErrs...)
+			valid = false
+		}
+	}
+	return valid
+}
+
+// fieldConfigName return the config name of a struct field from its tags
+func fieldConfigName(field reflect.StructField) (string, bool) {
+	for _, tagName := range []string{"config", "json"} {
+		tag, ok := field.Tag.Lookup(tagName)
+		if !ok {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "-" {
+			return "", true
+		}
+		if name != "" {
+			return name, false
+		}
+	}
+	return "", false
+}
+
+// lookupField find name in m, falling back to a case-insensitive match,
+// and return the matched config key
+func lookupField(m map[string]interface{}, name string) (string, interface{}, bool) {
+	if val, exists := m[name]; exists {
+		return name, val, true
+	}
+	for key, val := range m {
+		if strings.EqualFold(key, name) {
+			return key, val, true
+		}
+	}
+	return "", nil, false
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
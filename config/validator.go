@@ -4,6 +4,29 @@ type Validator interface {
 	Validate(keys []string) error
 }
 
+// SchemaValidator validate against declarative schemas and cross-field rules
+type SchemaValidator interface {
+	Validator
+	ValidateSchema(schema *Schema) error
+	ValidateRules(rules *RuleSet) error
+}
+
+var _ SchemaValidator = (*Config)(nil)
+
+// ConfigValidator validate a whole config; used by ConfigBuilder on Build
+// and by ConfigWatcher on every reload
+type ConfigValidator interface {
+	ValidateConfig(c *Config) error
+}
+
+// ConfigValidatorFunc adapt a function to the ConfigValidator interface
+type ConfigValidatorFunc func(c *Config) error
+
+// ValidateConfig call the function
+func (f ConfigValidatorFunc) ValidateConfig(c *Config) error {
+	return f(c)
+}
+
 func (c *Config) Validate(keys []string) error {
 	for _, key := range keys {
 		if _, err := c.GetValue(key); err != nil {
@@ -13,3 +36,22 @@ func (c *Config) Validate(keys []string) error {
 	}
 	return nil
 }
+
+// ValidateSchema check every field of the schema, returning a MultiError
+// with all the violations found
+func (c *Config) ValidateSchema(schema *Schema) error {
+	var errs []error
+	for _, field := range schema.fields {
+		errs = append(errs, field.validate(c)...)
+	}
+	if len(errs) > 0 {
+		return MultiError{Errors: errs}
+	}
+	return nil
+}
+
+// ValidateRules evaluate cross-key rules, returning a MultiError with all
+// the violations found
+func (c *Config) ValidateRules(rules *RuleSet) error {
+	return rules.ValidateConfig(c)
+}
@@ -1,49 +1,119 @@
 package config
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
 )
 
-// ConfigWatcher observe config files changes
+// DefaultDebounce is the window used to coalesce bursts of file events
+const DefaultDebounce = 100 * time.Millisecond
+
+// errorsBuffer is the capacity of the Errors channel; errors are dropped
+// when nobody reads it
+const errorsBuffer = 16
+
+// ErrWatcherStarted is returned by Run when the watcher was already started
+var ErrWatcherStarted = errors.New("config watcher was already started")
+
+// ConfigWatcher observe config files changes. On every reload the whole
+// recipe is rebuilt from scratch and swapped in, so the live config always
+// equals what a fresh start would produce.
 type ConfigWatcher struct {
-	config    *Config
-	filePaths []string
-	watcher   *fsnotify.Watcher
-	interval  time.Duration
-	callbacks []func(*Config)
-	stop      chan struct{}
+	config          *Config
+	recipe          *ConfigBuilder
+	filePaths       []string
+	watchedDirs     map[string]bool
+	dirs            []string
+	watcher         *fsnotify.Watcher
+	interval        time.Duration
+	debounce        time.Duration
+	callbacks       []func(*Config)
+	watches         []keyWatch
+	participants    []ReloadParticipant
+	onError         []func(error)
+	lastGood        string
+	status          sync.Mutex
+	lastErr         error
+	lastGoodAt      time.Time
+	log             *slog.Logger
+	errs            chan error
+	errsMu          sync.Mutex
+	errsClosed      bool
+	signals         []os.Signal
+	trigger         chan struct{}
+	reloadMu        sync.Mutex
+	hashMu          sync.Mutex
+	hashes          map[string]string
+	pollingOnly     bool
+	dispatchMode    DispatchMode
+	callbackTimeout time.Duration
+	started         atomic.Bool
+	stopOnce        sync.Once
+	stop            chan struct{}
+	done            chan struct{}
 }
 
-// NewConfigWatcher create new config observer
+// NewConfigWatcher create new config observer. When cfg was built by a
+// ConfigBuilder its recipe is replayed on reload and its files and sources
+// are watched too; otherwise the keys not loaded from files are kept as
+// the base layer.
 func NewConfigWatcher(cfg *Config, reloadInterval time.Duration, files ...string) (*ConfigWatcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return nil, err
 	}
 
-	// Add folders to watcher
-	dirs := make(map[string]bool)
+	w := &ConfigWatcher{
+		config:          cfg,
+		watchedDirs:     make(map[string]bool),
+		watcher:         watcher,
+		interval:        reloadInterval,
+		debounce:        DefaultDebounce,
+		callbackTimeout: DefaultCallbackTimeout,
+		lastGoodAt:      time.Now(),
+		errs:            make(chan error, errorsBuffer),
+		trigger:         make(chan struct{}, 1),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+
+	if cfg.recipe != nil {
+		w.recipe = cfg.recipe.clone()
+		w.lastGood = cfg.recipe.lastGood
+	} else {
+		w.recipe = NewConfigBuilder().
+			step(cfg.loadMigrations().replay()).
+			WithConfig(baseLayer(cfg, files))
+	}
+	for _, file := range w.recipe.files {
+		w.watchFile(file)
+	}
+	for _, src := range w.recipe.sources {
+		w.watchSource(src)
+	}
+
 	for _, file := range files {
-		dir := filepath.Dir(file)
-		if _, exists := dirs[dir]; !exists {
-			if err := watcher.Add(dir); err == nil {
-				dirs[dir] = true
-			}
+		if !w.recipe.hasFile(file) {
+			w.recipe.WithJSON(file)
 		}
+		w.watchFile(file)
 	}
 
-	return &ConfigWatcher{
-		config:    cfg,
-		filePaths: files,
-		watcher:   watcher,
-		interval:  reloadInterval,
-		stop:      make(chan struct{}),
-	}, nil
+	// Changes are detected by content, not by modification time
+	w.storeHashes(w.currentHashes())
+	return w, nil
 }
 
 // OnReload register callback for changes
@@ -51,83 +121,453 @@ func (w *ConfigWatcher) OnReload(callback func(*Config)) {
 	w.callbacks = append(w.callbacks, callback)
 }
 
-// Start observer
+// SetPollingOnly disable file system notifications and detect file changes
+// only by hashing their content on every tick, for NFS, FUSE and other
+// mounts where inotify does not work
+func (w *ConfigWatcher) SetPollingOnly(enabled bool) {
+	w.pollingOnly = enabled
+}
+
+// SetDebounce set the window used to coalesce file events: a reload runs
+// once no event arrived for d, so write-temp-then-rename saves reload once.
+// Zero reloads on every event.
+func (w *ConfigWatcher) SetDebounce(d time.Duration) {
+	w.debounce = d
+}
+
+// SetLogger set the logger used by the watcher (default: the config logger)
+func (w *ConfigWatcher) SetLogger(logger *slog.Logger) {
+	w.log = logger
+}
+
+// Errors return a channel receiving reload, poll and file watching
+// errors. It is buffered; errors are dropped when it is full. It is
+// closed once the watcher stops.
+func (w *ConfigWatcher) Errors() <-chan error {
+	return w.errs
+}
+
+// ReloadOnSignal reload when the process receives one of sigs
+// (default: SIGHUP) while the watcher runs
+func (w *ConfigWatcher) ReloadOnSignal(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	w.signals = append(w.signals, sigs...)
+}
+
+// Trigger return a channel requesting a reload from the running watcher.
+// It has a buffer of one, so a non-blocking send (select with default)
+// coalesces requests made while a reload is pending.
+func (w *ConfigWatcher) Trigger() chan<- struct{} {
+	return w.trigger
+}
+
+// Reload rebuild and apply the config now, running the same validators and
+// callbacks as file events; it returns the reload error, if any. Do not
+// call it from reload callbacks.
+func (w *ConfigWatcher) Reload() error {
+	return w.reloadConfig()
+}
+
+// OnReloadError register callback for reloads that failed to load or
+// validate; the last good config stays active
+func (w *ConfigWatcher) OnReloadError(callback func(error)) {
+	w.onError = append(w.onError, callback)
+}
+
+// LastError return the error of the last reload, or nil if it succeeded
+func (w *ConfigWatcher) LastError() error {
+	w.status.Lock()
+	defer w.status.Unlock()
+	return w.lastErr
+}
+
+// LastGoodAt return when the active config was applied
+func (w *ConfigWatcher) LastGoodAt() time.Time {
+	w.status.Lock()
+	defer w.status.Unlock()
+	return w.lastGoodAt
+}
+
+// PersistLastGood write the active config to filePath now and after every
+// successful reload, for use as a startup fallback (see LoadLastGood).
+// The tree is written as it was before processors ran, so secret
+// references and ENC[...] values stay unresolved; the file is still
+// created with 0600 permissions.
+func (w *ConfigWatcher) PersistLastGood(filePath string) error {
+	w.lastGood = filePath
+	return SaveLastGood(w.config, filePath)
+}
+
+// OnChange register callback receiving the key-level changes of every
+// reload that changed something
+func (w *ConfigWatcher) OnChange(callback func(ChangeEvent)) {
+	w.Watch("", callback)
+}
+
+// Watch register callback fired only when keyOrPrefix or a key under it
+// changed; the event holds only those changes
+func (w *ConfigWatcher) Watch(keyOrPrefix string, callback func(ChangeEvent)) {
+	w.watches = append(w.watches, keyWatch{prefix: keyOrPrefix, callback: callback})
+}
+
+// AddSource register a source reloaded with the files.
+// PollingSource implementations are polled on every tick and the paths of
+// WatchedSource implementations are observed for file events.
+func (w *ConfigWatcher) AddSource(src Source) {
+	if w.recipe.hasSource(src) {
+		return // Already loaded and watched through the builder recipe
+	}
+	w.recipe.WithSource(src)
+	w.watchSource(src)
+}
+
+// AddProcessor register a processor applied to every reloaded config.
+// PollingProcessor implementations are polled on every tick.
+func (w *ConfigWatcher) AddProcessor(p Processor) {
+	if !w.recipe.hasProcessor(p) {
+		w.recipe.WithProcessor(p)
+	}
+}
+
+// AddValidator register a validator run on every reload; invalid
+// configs are not applied
+func (w *ConfigWatcher) AddValidator(v ConfigValidator) {
+	w.recipe.WithValidator(v)
+}
+
+func (w *ConfigWatcher) watchFile(file string) {
+	for _, existing := range w.filePaths {
+		if existing == file {
+			return
+		}
+	}
+	w.filePaths = append(w.filePaths, file)
+
+	// Watch the folder so atomic saves and created files are seen
+	dir := filepath.Dir(file)
+	if !w.watchedDirs[dir] {
+		if err := w.watcher.Add(dir); err == nil {
+			w.watchedDirs[dir] = true
+		}
+	}
+}
+
+func (w *ConfigWatcher) watchSource(src Source) {
+	watched, ok := src.(WatchedSource)
+	if !ok {
+		return
+	}
+	for _, path := range watched.WatchPaths() {
+		// Still hashed on every tick when notifications are unavailable
+		path = filepath.Clean(path)
+		w.dirs = append(w.dirs, path)
+		w.storeHash(path, contentHash(path))
+		if err := w.watcher.Add(path); err != nil {
+			w.report(fmt.Errorf("watch error for %s: %w", path, err))
+		}
+	}
+}
+
+// Start observer; blocks until Stop is called
 func (w *ConfigWatcher) Start() {
+	w.Run(context.Background())
+}
+
+// Run observe changes until ctx is cancelled or Stop is called, returning
+// ctx.Err() in the first case and nil in the second
+func (w *ConfigWatcher) Run(ctx context.Context) error {
+	if !w.started.CompareAndSwap(false, true) {
+		return ErrWatcherStarted
+	}
+	defer close(w.done)
+	defer w.closeErrors()
+	defer w.watcher.Close()
+
 	ticker := time.NewTicker(w.interval)
 	defer ticker.Stop()
 
+	events, errs := w.watcher.Events, w.watcher.Errors
+	if w.pollingOnly {
+		w.watcher.Close()
+		events, errs = nil, nil // Never ready
+	}
+
+	pending := time.NewTimer(w.debounce)
+	pending.Stop()
+	defer pending.Stop()
+
+	var signals chan os.Signal
+	if len(w.signals) > 0 {
+		signals = make(chan os.Signal, 1)
+		signal.Notify(signals, w.signals...)
+		defer signal.Stop(signals)
+	}
+
+	scheduleReload := func() {
+		if w.debounce <= 0 {
+			w.reloadConfig()
+			return
+		}
+		pending.Reset(w.debounce) // Restart the window on every event
+	}
+
 	for {
 		select {
-		case event, ok := <-w.watcher.Events:
+		case event, ok := <-events:
 			if !ok {
-				return
+				return nil
 			}
-			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
-				for _, file := range w.filePaths {
-					if filepath.Clean(event.Name) == filepath.Clean(file) {
-						w.reloadConfig()
-						break
-					}
-				}
+			w.handleRemovedDir(event)
+			if w.isRelevant(event) {
+				scheduleReload()
 			}
 
-		case err, ok := <-w.watcher.Errors:
+		case <-pending.C:
+			if w.contentChanged() {
+				w.reloadConfig() // Skip touches that left the content intact
+			}
+
+		case <-signals:
+			pending.Stop()
+			w.reloadConfig()
+
+		case <-w.trigger:
+			pending.Stop()
+			w.reloadConfig()
+
+		case err, ok := <-errs:
 			if !ok {
-				return
+				return nil
 			}
-			log.Printf("Config watcher error: %v", err)
+			w.report(fmt.Errorf("watch error: %w", err))
 
 		case <-ticker.C:
+			if !w.pollingOnly {
+				w.rearm()
+			}
+
 			// Verify changes
-			for _, file := range w.filePaths {
-				if w.fileChanged(file) {
-					w.reloadConfig()
-					break
-				}
+			if w.sourcesChanged() || w.contentChanged() {
+				// Polling already coalesces changes over the interval
+				pending.Stop()
+				w.reloadConfig()
 			}
 
+		case <-ctx.Done():
+			return ctx.Err()
+
 		case <-w.stop:
-			w.watcher.Close()
-			return
+			return nil
 		}
 	}
 }
 
-// Stop watcher
+// Stop watcher and wait for the loop to exit; safe to call more than once
 func (w *ConfigWatcher) Stop() {
-	close(w.stop)
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+	if w.started.Load() {
+		<-w.done
+		return
+	}
+	w.watcher.Close()
+	w.closeErrors()
 }
 
-func (w *ConfigWatcher) fileChanged(filePath string) bool {
-	info, err := os.Stat(filePath)
-	if err != nil {
-		return false
+// isRelevant report if an event affects a watched file or source directory.
+// Kubernetes updates mounted volumes by swapping the "..data" symlink, so
+// that event counts for every file or source in the same directory.
+func (w *ConfigWatcher) isRelevant(event fsnotify.Event) bool {
+	name := filepath.Clean(event.Name)
+	dir, base := filepath.Dir(name), filepath.Base(name)
+	swapped := base == kubernetesDataDir && event.Has(fsnotify.Create)
+
+	// Atomic saves rename a temp file over the target (Create) and some
+	// editors move the original away first (Rename/Remove)
+	if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) ||
+		event.Has(fsnotify.Rename) || event.Has(fsnotify.Remove) {
+		for _, file := range w.filePaths {
+			file = filepath.Clean(file)
+			if name == file || (swapped && dir == filepath.Dir(file)) {
+				return true
+			}
+		}
 	}
-	return info.ModTime().After(w.config.LastModified)
+
+	for _, sourceDir := range w.dirs {
+		if dir != sourceDir || event.Op == fsnotify.Chmod {
+			continue
+		}
+		if swapped || !strings.HasPrefix(base, ".") {
+			return true
+		}
+	}
+	return false
 }
 
-func (w *ConfigWatcher) reloadConfig() {
-	newConfig := NewConfig()
-	success := true
+// handleRemovedDir forget the watch of a removed or renamed folder so
+// rearm can add it again once it is recreated
+func (w *ConfigWatcher) handleRemovedDir(event fsnotify.Event) {
+	if !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return
+	}
+	dir := filepath.Clean(event.Name)
+	if w.watchedDirs[dir] {
+		delete(w.watchedDirs, dir)
+		w.watcher.Remove(dir)
+	}
+}
 
+// rearm watch again the folders of files that were missing or removed
+func (w *ConfigWatcher) rearm() {
 	for _, file := range w.filePaths {
-		if err := newConfig.LoadFromJSON(file); err != nil {
-			log.Printf("Error reloading config: %v", err)
-			success = false
-			// Continue trying with other files
+		dir := filepath.Dir(file)
+		if w.watchedDirs[dir] {
+			continue
+		}
+		if err := w.watcher.Add(dir); err == nil {
+			w.watchedDirs[dir] = true
+		}
+	}
+}
+
+// sourcesChanged poll every PollingSource and PollingProcessor and report if any changed
+func (w *ConfigWatcher) sourcesChanged() bool {
+	var pollers []interface{ Changed() (bool, error) }
+	for _, src := range w.recipe.sources {
+		if polling, ok := src.(PollingSource); ok {
+			pollers = append(pollers, polling)
+		}
+	}
+	for _, p := range w.recipe.processors {
+		if polling, ok := p.(PollingProcessor); ok {
+			pollers = append(pollers, polling)
+		}
+	}
+
+	changed := false
+	for _, poller := range pollers {
+		pollChanged, err := poller.Changed()
+		if err != nil {
+			w.report(fmt.Errorf("poll error: %w", err))
+			continue
 		}
+		changed = changed || pollChanged
 	}
+	return changed
+}
+
+// reloadConfig run the reload pipeline shared by every trigger
+func (w *ConfigWatcher) reloadConfig() error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
 
-	if !success {
-		return // No apply changes or invoke callbacks
+	// Remember what this attempt read so a broken file is not retried every tick
+	w.storeHashes(w.currentHashes())
+
+	newConfig, err := w.recipe.build(w.config)
+	if err != nil {
+		return w.reloadFailed(err) // Keep the last good config, no callbacks
 	}
+	if err := w.prepare(newConfig); err != nil {
+		return w.reloadFailed(err)
+	}
+
+	// Swap in the rebuilt config so removed keys disappear
+	changes := w.config.replace(newConfig)
+	if len(changes) > 0 {
+		// Caches and dynamic values first, so callbacks read fresh values
+		w.dispatch(w.config.listenerCalls(ChangeEvent{Changes: changes}.Keys()))
+	}
+	w.commit(w.config)
 
-	// Update main config
-	w.config.Merge(newConfig)
-	w.config.LastModified = time.Now()
+	w.status.Lock()
+	w.lastErr = nil
+	w.lastGoodAt = w.config.LastModified
+	w.status.Unlock()
+
+	if w.lastGood != "" {
+		if err := SaveLastGood(w.config, w.lastGood); err != nil {
+			w.report(err)
+		}
+	}
 
 	// Invoke callbacks
-	for _, callback := range w.callbacks {
-		callback(w.config)
+	w.dispatch(w.reloadCallbacks(changes))
+	return nil
+}
+
+func (w *ConfigWatcher) reloadFailed(err error) error {
+	err = fmt.Errorf("reload error: %w", err)
+	w.report(err)
+
+	w.status.Lock()
+	w.lastErr = err
+	w.status.Unlock()
+
+	w.dispatch(w.errorCallbacks(err))
+	return err
+}
+
+// report log err and send it to the Errors channel without blocking;
+// errors reported after Stop (like late callbacks) are only logged
+func (w *ConfigWatcher) report(err error) {
+	w.logger().Error("config watcher error", "error", err)
+	w.errsMu.Lock()
+	defer w.errsMu.Unlock()
+	if w.errsClosed {
+		return
+	}
+	select {
+	case w.errs <- err:
+	default:
+	}
+}
+
+// closeErrors close the Errors channel once
+func (w *ConfigWatcher) closeErrors() {
+	w.errsMu.Lock()
+	defer w.errsMu.Unlock()
+	if !w.errsClosed {
+		w.errsClosed = true
+		close(w.errs)
+	}
+}
+
+func (w *ConfigWatcher) logger() *slog.Logger {
+	if w.log != nil {
+		return w.log
+	}
+	return w.config.Logger()
+}
+
+// keyWatch is a Watch subscription
+type keyWatch struct {
+	prefix   string
+	callback func(ChangeEvent)
+}
+
+// baseLayer return a copy of cfg without the keys loaded from files, used
+// as the first layer when cfg was not built by a ConfigBuilder
+func baseLayer(cfg *Config, files []string) *Config {
+	watched := make(map[string]bool, len(files))
+	for _, file := range files {
+		watched[file] = true
+	}
+
+	base := &Config{Data: cloneMap(cfg.data()), origins: make(map[string]string)}
+	if base.Data == nil {
+		base.Data = make(map[string]interface{})
+	}
+	for key, origin := range cfg.origins {
+		if watched[origin] {
+			deleteKey(base.Data, key)
+			continue
+		}
+		base.origins[key] = origin
 	}
+	return base
 }
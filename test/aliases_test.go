@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAliasesAndDeprecations(t *testing.T) {
+	dir := t.TempDir()
+	legacyPath := filepath.Join(dir, "legacy.json")
+	require.NoError(t, os.WriteFile(legacyPath, []byte(`{"db": {"hostname": "legacy.internal", "pool": 5}}`), 0644))
+	modernPath := filepath.Join(dir, "modern.json")
+	require.NoError(t, os.WriteFile(modernPath, []byte(`{"db": {"host": "modern.internal"}}`), 0644))
+
+	t.Run("Loaders rewrite old keys", func(t *testing.T) {
+		var warnings []config.DeprecationWarning
+		cfg, err := config.NewConfigBuilder().
+			OnDeprecated(func(w config.DeprecationWarning) { warnings = append(warnings, w) }).
+			WithDeprecation("db.hostname", "db.host", "removed in v3").
+			WithJSON(legacyPath).
+			Build()
+		require.NoError(t, err)
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "legacy.internal", host)
+
+		// Old reads still work through the alias
+		old, err := cfg.GetString("db.hostname")
+		require.NoError(t, err)
+		assert.Equal(t, "legacy.internal", old)
+
+		db, _ := cfg.GetValue("db")
+		assert.NotContains(t, db, "hostname")
+
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "db.hostname", warnings[0].Key)
+		assert.Equal(t, "db.host", warnings[0].Replacement)
+		assert.Equal(t, legacyPath, warnings[0].Source)
+		assert.Equal(t, "config key 'db.hostname' is deprecated, use 'db.host' (found in "+legacyPath+"): removed in v3",
+			warnings[0].String())
+	})
+
+	t.Run("Precedence follows load order", func(t *testing.T) {
+		cfg := config.NewConfigBuilder().
+			WithAlias("db.hostname", "db.host").
+			WithJSON(modernPath).
+			WithJSON(legacyPath).
+			MustBuild()
+		host, _ := cfg.GetString("db.host")
+		assert.Equal(t, "legacy.internal", host)
+
+		cfg = config.NewConfigBuilder().
+			WithAlias("db.hostname", "db.host").
+			WithJSON(legacyPath).
+			WithJSON(modernPath).
+			MustBuild()
+		host, _ = cfg.GetString("db.host")
+		assert.Equal(t, "modern.internal", host)
+	})
+
+	t.Run("Overrides of old keys win", func(t *testing.T) {
+		var warnings []config.DeprecationWarning
+		cfg, err := config.NewConfigBuilder().
+			OnDeprecated(func(w config.DeprecationWarning) { warnings = append(warnings, w) }).
+			WithAlias("db.hostname", "db.host").
+			WithDeprecation("db.replicas_old", "db.replicas", "").
+			WithJSON(modernPath).
+			WithOverrides([]string{"db.hostname=override", "db.replicas_old[1]=r1"}).
+			Build()
+		require.NoError(t, err)
+
+		host, _ := cfg.GetString("db.host")
+		assert.Equal(t, "override", host)
+		replicas, _ := cfg.GetValue("db.replicas")
+		assert.Equal(t, []interface{}{nil, "r1"}, replicas)
+
+		db, _ := cfg.GetValue("db")
+		assert.NotContains(t, db, "hostname")
+		assert.NotContains(t, db, "replicas_old")
+
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "override", warnings[0].Source)
+	})
+
+	t.Run("Warnings fire once per key", func(t *testing.T) {
+		count := 0
+		cfg := config.NewConfig()
+		cfg.OnDeprecated(func(w config.DeprecationWarning) { count++ })
+		cfg.Deprecate("db.pool", "", "pool is sized automatically")
+
+		require.NoError(t, cfg.LoadFromJSON(legacyPath))
+		require.NoError(t, cfg.LoadFromJSON(legacyPath))
+		assert.Equal(t, 1, count)
+
+		// Deprecated keys without replacement are kept
+		pool, err := cfg.GetInt("db.pool")
+		require.NoError(t, err)
+		assert.Equal(t, 5, pool)
+	})
+
+	t.Run("Registering rewrites existing data", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"database": map[string]interface{}{"host": "a", "port": 1.0},
+		})
+		cfg.RegisterAlias("database", "db")
+
+		assert.Equal(t, "db.port", cfg.ResolveAlias("database.port"))
+		port, err := cfg.GetInt("database.port")
+		require.NoError(t, err)
+		assert.Equal(t, 1, port)
+		_, exists := cfg.Data["database"]
+		assert.False(t, exists)
+		assert.Contains(t, cfg.Data, "db")
+	})
+
+	t.Run("Env vars are renamed", func(t *testing.T) {
+		t.Setenv("ALIASTEST_DB_HOSTNAME", "env.internal")
+
+		var warnings []config.DeprecationWarning
+		cfg := config.NewConfig()
+		cfg.OnDeprecated(func(w config.DeprecationWarning) { warnings = append(warnings, w) })
+		cfg.Deprecate("db.hostname", "db.host", "")
+		require.NoError(t, cfg.LoadFromEnv("ALIASTEST_"))
+
+		assert.Equal(t, "env.internal", cfg.Data["db.host"])
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "env ALIASTEST_DB_HOSTNAME", warnings[0].Source)
+	})
+	t.Run("Reloads do not share aliases with readers", func(t *testing.T) {
+		count := 0
+		cfg := config.NewConfigBuilder().
+			WithDeprecation("db.hostname", "db.host", "").
+			OnDeprecated(func(w config.DeprecationWarning) { count++ }).
+			WithJSON(legacyPath).
+			MustBuild()
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		defer watcher.Stop()
+
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+		started := make(chan struct{})
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			close(started)
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					_, _ = cfg.GetValue("missing.key")
+					_, _ = cfg.GetString("db.hostname")
+				}
+			}
+		}()
+		<-started
+		for i := 0; i < 100; i++ {
+			require.NoError(t, watcher.Reload())
+		}
+		close(stop)
+		wg.Wait()
+
+		host, _ := cfg.GetString("db.hostname")
+		assert.Equal(t, "legacy.internal", host)
+		assert.Equal(t, 1, count, "Warnings still fire once across reloads")
+	})
+
+	t.Run("Reloads keep aliases registered on plain configs", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.RegisterAlias("db.hostname", "db.host")
+		require.NoError(t, cfg.LoadFromJSON(legacyPath))
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour, legacyPath)
+		require.NoError(t, err)
+		defer watcher.Stop()
+		require.NoError(t, watcher.Reload())
+
+		assert.Equal(t, "db.host", cfg.ResolveAlias("db.hostname"))
+		host, _ := cfg.GetString("db.hostname")
+		assert.Equal(t, "legacy.internal", host)
+	})
+}
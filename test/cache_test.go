@@ -0,0 +1,234 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigWithCacheInvalidation(t *testing.T) {
+	newCached := func() (*config.Config, *config.ConfigWithCache) {
+		base := config.NewConfig()
+		base.SetData(map[string]interface{}{
+			"db":  map[string]interface{}{"host": "a", "port": 1.0},
+			"app": map[string]interface{}{"name": "x"},
+		})
+		return base, config.NewConfigWithCache(base)
+	}
+
+	t.Run("Merge invalidates the merged keys only", func(t *testing.T) {
+		base, cached := newCached()
+		host, _ := cached.GetString("db.host")
+		assert.Equal(t, "a", host)
+		name, _ := cached.GetString("app.name")
+		assert.Equal(t, "x", name)
+
+		other := config.NewConfig()
+		other.SetData(map[string]interface{}{"db": map[string]interface{}{"host": "b"}})
+		cached.Merge(other)
+
+		host, _ = cached.GetString("db.host")
+		assert.Equal(t, "b", host)
+
+		// Unrelated keys stay cached: a change behind the cache's back is not seen
+		base.Data["app"].(map[string]interface{})["name"] = "changed in place"
+		name, _ = cached.GetString("app.name")
+		assert.Equal(t, "x", name)
+	})
+
+	t.Run("Parent and child paths are invalidated", func(t *testing.T) {
+		base, cached := newCached()
+		_, _ = cached.GetString("db.port")
+
+		base.Set("db", map[string]interface{}{"port": 2.0})
+		port, _ := cached.GetInt("db.port")
+		assert.Equal(t, 2, port)
+
+		base.Set("db.port.extra", true)
+		_, err := cached.GetInt("db.port")
+		assert.Error(t, err, "Cached value must be dropped when a child changes")
+
+		base.SetData(map[string]interface{}{"app": map[string]interface{}{"name": "y"}})
+		name, _ := cached.GetString("app.name")
+		assert.Equal(t, "y", name)
+	})
+
+	t.Run("Aliased keys follow their target", func(t *testing.T) {
+		base, cached := newCached()
+		base.RegisterAlias("database", "db")
+		host, _ := cached.GetString("database.host")
+		assert.Equal(t, "a", host)
+
+		base.Set("db.host", "c")
+		host, _ = cached.GetString("database.host")
+		assert.Equal(t, "c", host)
+	})
+
+	t.Run("Watcher reloads invalidate changed keys", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "a"}, "app": {"name": "x"}}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+		cached := config.NewConfigWithCache(cfg)
+
+		host, _ := cached.GetString("db.host")
+		assert.Equal(t, "a", host)
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "b"}, "app": {"name": "x"}}`), 0644))
+
+		assert.Eventually(t, func() bool {
+			host, _ := cached.GetString("db.host")
+			return host == "b"
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+}
+
+func TestConfigWithCacheTypes(t *testing.T) {
+	newCached := func() (*config.Config, *config.ConfigWithCache) {
+		base := config.NewConfig()
+		base.SetData(map[string]interface{}{
+			"db": map[string]interface{}{
+				"port":    5432.0,
+				"timeout": "5s",
+				"hosts":   []interface{}{"a", "b"},
+			},
+			"debug": "true",
+		})
+		return base, config.NewConfigWithCache(base)
+	}
+
+	t.Run("Same key read as different types", func(t *testing.T) {
+		_, cached := newCached()
+
+		port, err := cached.GetString("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, "5432", port)
+
+		assert.NotPanics(t, func() {
+			portInt, err := cached.GetInt("db.port")
+			require.NoError(t, err)
+			assert.Equal(t, 5432, portInt)
+		})
+
+		debug, err := cached.GetBool("debug")
+		require.NoError(t, err)
+		assert.True(t, debug)
+		debugStr, _ := cached.GetString("debug")
+		assert.Equal(t, "true", debugStr)
+	})
+
+	t.Run("GetValue is cached and invalidated", func(t *testing.T) {
+		base, cached := newCached()
+
+		val, err := cached.GetValue("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5432.0, val)
+
+		base.Set("db.port", 6543.0)
+		val, _ = cached.GetValue("db.port")
+		assert.Equal(t, 6543.0, val)
+		port, _ := cached.GetInt("db.port")
+		assert.Equal(t, 6543, port)
+	})
+
+	t.Run("Generic getter", func(t *testing.T) {
+		base, cached := newCached()
+
+		timeout, err := config.Get[time.Duration](cached, "db.timeout")
+		require.NoError(t, err)
+		assert.Equal(t, 5*time.Second, timeout)
+
+		hosts, err := config.Get[[]string](cached, "db.hosts")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, hosts)
+
+		port, err := config.Get[int](cached, "db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5432, port)
+
+		// Uncached configs decode the same way
+		port, err = config.Get[int](base, "db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5432, port)
+
+		_, err = config.Get[int](cached, "db.missing")
+		assert.Error(t, err)
+
+		base.Set("db.timeout", "1m")
+		timeout, _ = config.Get[time.Duration](cached, "db.timeout")
+		assert.Equal(t, time.Minute, timeout)
+	})
+
+	t.Run("InvalidateKey drops every type", func(t *testing.T) {
+		base, cached := newCached()
+		_, _ = cached.GetString("db.port")
+		_, _ = cached.GetInt("db.port")
+
+		base.Data["db"].(map[string]interface{})["port"] = 1.0 // Behind the cache's back
+		cached.InvalidateKey("db.port")
+
+		port, _ := cached.GetInt("db.port")
+		assert.Equal(t, 1, port)
+		portStr, _ := cached.GetString("db.port")
+		assert.Equal(t, "1", portStr)
+	})
+
+	t.Run("Implements Configurer", func(t *testing.T) {
+		_, cached := newCached()
+		var c config.Configurer = cached
+		assert.NoError(t, c.Validate([]string{"db.port"}))
+	})
+	t.Run("Close detaches the cache", func(t *testing.T) {
+		base, cached := newCached()
+		_, _ = cached.GetInt("db.port")
+
+		cached.Close()
+		base.Set("db.port", 1.0)
+		port, _ := cached.GetInt("db.port")
+		assert.Equal(t, 5432, port, "No longer invalidated")
+	})
+	t.Run("Loads racing an invalidation are not cached", func(t *testing.T) {
+		base, cached := newCached()
+
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+						_, _ = cached.GetInt("db.port")
+					}
+				}
+			}()
+		}
+		stale := 0
+		for i := 0; i < 2000; i++ {
+			base.Set("db.port", float64(i))
+			if port, _ := cached.GetInt("db.port"); port != i {
+				stale++
+			}
+		}
+		close(stop)
+		wg.Wait()
+		assert.Zero(t, stale)
+	})
+}
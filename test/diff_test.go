@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigDiff(t *testing.T) {
+	t.Run("Added, removed and changed keys", func(t *testing.T) {
+		old := config.NewConfig()
+		old.SetData(map[string]interface{}{
+			"db":   map[string]interface{}{"host": "a", "port": 1.0, "pool": 5.0},
+			"tags": []interface{}{"x"},
+		})
+		updated := config.NewConfig()
+		updated.SetData(map[string]interface{}{
+			"db":    map[string]interface{}{"host": "b", "port": 1.0},
+			"tags":  []interface{}{"x", "y"},
+			"level": "debug",
+		})
+
+		changes := withoutConfig(old.Diff(updated))
+		assert.Equal(t, []config.Change{
+			{Key: "db.host", Type: config.ChangeModified, OldValue: "a", NewValue: "b"},
+			{Key: "db.pool", Type: config.ChangeRemoved, OldValue: 5.0},
+			{Key: "level", Type: config.ChangeAdded, NewValue: "debug"},
+			{Key: "tags", Type: config.ChangeModified, OldValue: []interface{}{"x"}, NewValue: []interface{}{"x", "y"}},
+		}, changes)
+		assert.Empty(t, old.Diff(old))
+	})
+
+	t.Run("Events filter by prefix and mask sensitive values", func(t *testing.T) {
+		cfg := config.NewConfig()
+		event := config.ChangeEvent{
+			Config: cfg,
+			Changes: []config.Change{
+				{Key: "db.host", Type: config.ChangeModified, OldValue: "a", NewValue: "b"},
+				{Key: "db.password", Type: config.ChangeModified, OldValue: "old", NewValue: "new"},
+				{Key: "level", Type: config.ChangeAdded, NewValue: "debug"},
+			},
+		}
+
+		assert.True(t, event.Has("db"))
+		assert.True(t, event.Has("db.host"))
+		assert.False(t, event.Has("app"))
+		assert.Equal(t, []string{"db.host", "db.password"}, event.Filter("db").Keys())
+
+		change, ok := event.Get("db.password")
+		require.True(t, ok)
+		assert.Equal(t, "new", change.NewValue)
+
+		assert.Equal(t, "~ db.host: a -> b\n~ db.password: [REDACTED] -> [REDACTED]\n+ level = debug", event.String())
+	})
+
+	t.Run("Diff output masks sensitive values", func(t *testing.T) {
+		old := config.NewConfig()
+		old.MarkSensitive("internal.*")
+		updated := config.NewConfig()
+		updated.SetData(map[string]interface{}{
+			"db":       map[string]interface{}{"password": "hunter2"},
+			"internal": map[string]interface{}{"seed": "42"},
+		})
+
+		changes := old.Diff(updated)
+		require.Len(t, changes, 2)
+		assert.Equal(t, "+ db.password = [REDACTED]", changes[0].String())
+		assert.Equal(t, "+ internal.seed = [REDACTED]", changes[1].String())
+		assert.Equal(t, "hunter2", changes[0].NewValue, "Values stay raw")
+
+		// Changes built by hand use the default patterns
+		change := config.Change{Key: "api.token", Type: config.ChangeRemoved, OldValue: "abc"}
+		assert.Equal(t, "- api.token (was [REDACTED])", change.String())
+	})
+}
+
+// withoutConfig keep only the exported fields of changes, for comparisons
+func withoutConfig(changes []config.Change) []config.Change {
+	plain := make([]config.Change, len(changes))
+	for i, change := range changes {
+		plain[i] = config.Change{Key: change.Key, Type: change.Type, OldValue: change.OldValue, NewValue: change.NewValue}
+	}
+	return plain
+}
+
+func TestWatcherChangeEvents(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "a", "pool": 5}, "app": {"name": "x"}}`), 0644))
+
+	cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+	require.NoError(t, err)
+	cfg.LastModified = time.Now()
+
+	watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+	require.NoError(t, err)
+
+	allCh := make(chan config.ChangeEvent, 4)
+	dbCh := make(chan config.ChangeEvent, 4)
+	appCh := make(chan config.ChangeEvent, 4)
+	watcher.OnChange(func(e config.ChangeEvent) { allCh <- e })
+	watcher.Watch("db", func(e config.ChangeEvent) { dbCh <- e })
+	watcher.Watch("app", func(e config.ChangeEvent) { appCh <- e })
+
+	go watcher.Start()
+	defer watcher.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "b"}, "app": {"name": "x"}, "level": "debug"}`), 0644))
+
+	select {
+	case e := <-dbCh:
+		assert.Equal(t, []string{"db.host", "db.pool"}, e.Keys())
+		change, _ := e.Get("db.host")
+		assert.Equal(t, "a", change.OldValue)
+		assert.Equal(t, "b", change.NewValue)
+		assert.Same(t, cfg, e.Config)
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting change event")
+	}
+
+	e := <-allCh
+	assert.Equal(t, []string{"db.host", "db.pool", "level"}, e.Keys())
+
+	select {
+	case <-appCh:
+		t.Fatal("Unchanged subtrees must not be notified")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
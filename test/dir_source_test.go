@@ -0,0 +1,148 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mountVolume write files the way Kubernetes does for ConfigMaps: into a
+// timestamped directory exposed through the "..data" symlink
+func mountVolume(t *testing.T, dir, version string, files map[string]string) {
+	versionDir := filepath.Join(dir, ".."+version)
+	require.NoError(t, os.Mkdir(versionDir, 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(versionDir, name), []byte(content), 0644))
+	}
+
+	tmpLink := filepath.Join(dir, "..data_tmp")
+	require.NoError(t, os.Symlink(filepath.Base(versionDir), tmpLink))
+	require.NoError(t, os.Rename(tmpLink, filepath.Join(dir, "..data")))
+
+	for name := range files {
+		link := filepath.Join(dir, name)
+		if _, err := os.Lstat(link); os.IsNotExist(err) {
+			require.NoError(t, os.Symlink(filepath.Join("..data", name), link))
+		}
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	t.Run("File names map to keys", func(t *testing.T) {
+		dir := t.TempDir()
+		mountVolume(t, dir, "v1", map[string]string{
+			"db__host": "db.internal",
+			"db__port": "5432",
+			"features": `{"beta": true}`,
+		})
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0644))
+
+		src := config.NewDirSource(dir).WithSeparator("__").WithJSONValues()
+		cfg, err := config.NewConfigBuilder().WithSource(src).Build()
+		require.NoError(t, err)
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "db.internal", host)
+
+		port, err := cfg.GetInt("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5432, port)
+
+		beta, err := cfg.GetBool("features.beta")
+		require.NoError(t, err)
+		assert.True(t, beta)
+
+		_, err = cfg.GetValue(".hidden")
+		assert.Error(t, err)
+		_, err = cfg.GetValue("..data")
+		assert.Error(t, err)
+	})
+
+	t.Run("Values are raw strings by default", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("abc\n"), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "limit"), []byte("10"), 0644))
+
+		data, err := config.NewDirSource(dir).Load()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"token": "abc\n", "limit": "10"}, data)
+	})
+
+	t.Run("Missing directory fails", func(t *testing.T) {
+		_, err := config.NewDirSource(filepath.Join(t.TempDir(), "missing")).Load()
+		assert.Error(t, err)
+	})
+
+	t.Run("Watcher detects ..data symlink swap", func(t *testing.T) {
+		dir := t.TempDir()
+		mountVolume(t, dir, "v1", map[string]string{"level": "info"})
+
+		src := config.NewDirSource(dir)
+		cfg, err := config.NewConfigBuilder().WithSource(src).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		watcher.AddSource(src)
+
+		reloadCh := make(chan string, 1)
+		watcher.OnReload(func(c *config.Config) {
+			level, _ := c.GetString("level")
+			select {
+			case reloadCh <- level:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		mountVolume(t, dir, "v2", map[string]string{"level": "debug"})
+
+		select {
+		case level := <-reloadCh:
+			assert.Equal(t, "debug", level)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting ..data swap reload")
+		}
+	})
+
+	t.Run("Watcher detects swap for mounted JSON files", func(t *testing.T) {
+		dir := t.TempDir()
+		mountVolume(t, dir, "v1", map[string]string{"config.json": `{"level": "info"}`})
+		filePath := filepath.Join(dir, "config.json")
+
+		cfg := config.NewConfig()
+		require.NoError(t, cfg.LoadFromJSON(filePath))
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour, filePath)
+		require.NoError(t, err)
+
+		reloadCh := make(chan string, 1)
+		watcher.OnReload(func(c *config.Config) {
+			level, _ := c.GetString("level")
+			select {
+			case reloadCh <- level:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		mountVolume(t, dir, "v2", map[string]string{"config.json": `{"level": "debug"}`})
+
+		select {
+		case level := <-reloadCh:
+			assert.Equal(t, "debug", level)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting ..data swap reload")
+		}
+	})
+}
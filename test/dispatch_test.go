@@ -0,0 +1,141 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackDispatch(t *testing.T) {
+	setup := func(t *testing.T) (string, *config.ConfigWatcher) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "info"}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		t.Cleanup(watcher.Stop)
+		return filePath, watcher
+	}
+
+	nextCallbackError := func(t *testing.T, watcher *config.ConfigWatcher) *config.CallbackError {
+		select {
+		case err := <-watcher.Errors():
+			var callbackErr *config.CallbackError
+			require.True(t, errors.As(err, &callbackErr), "unexpected error: %v", err)
+			return callbackErr
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting callback error")
+			return nil
+		}
+	}
+
+	t.Run("Panicking callbacks are isolated", func(t *testing.T) {
+		filePath, watcher := setup(t)
+
+		var order []string
+		watcher.OnReload(func(c *config.Config) { order = append(order, "first") })
+		watcher.OnReload(func(c *config.Config) { panic("boom") })
+		watcher.OnReload(func(c *config.Config) { order = append(order, "third") })
+		watcher.Watch("level", func(e config.ChangeEvent) { panic("watch boom") })
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "debug"}`), 0644))
+		require.NoError(t, watcher.Reload())
+		assert.Equal(t, []string{"first", "third"}, order)
+
+		err := nextCallbackError(t, watcher)
+		assert.Equal(t, "OnReload #2", err.Callback)
+		assert.Equal(t, "boom", err.Panic)
+		assert.NotEmpty(t, err.Stack)
+		assert.Equal(t, "callback OnReload #2 panicked: boom", err.Error())
+
+		err = nextCallbackError(t, watcher)
+		assert.Equal(t, "Watch #1 'level'", err.Callback)
+	})
+
+	t.Run("Slow callbacks time out", func(t *testing.T) {
+		_, watcher := setup(t)
+		watcher.SetCallbackTimeout(50 * time.Millisecond)
+
+		release := make(chan struct{})
+		defer close(release)
+		watcher.OnReload(func(c *config.Config) { <-release })
+
+		start := time.Now()
+		require.NoError(t, watcher.Reload())
+		assert.Less(t, time.Since(start), time.Second)
+
+		err := nextCallbackError(t, watcher)
+		assert.Equal(t, 50*time.Millisecond, err.Timeout)
+		assert.Equal(t, "callback OnReload #1 timed out after 50ms", err.Error())
+	})
+
+	t.Run("Parallel dispatch", func(t *testing.T) {
+		_, watcher := setup(t)
+		watcher.SetDispatchMode(config.DispatchParallel)
+		watcher.SetCallbackTimeout(time.Second)
+
+		// Each callback waits for the other, so ordered dispatch would time out
+		var ready sync.WaitGroup
+		ready.Add(2)
+		var mu sync.Mutex
+		done := 0
+		for i := 0; i < 2; i++ {
+			watcher.OnReload(func(c *config.Config) {
+				ready.Done()
+				ready.Wait()
+				mu.Lock()
+				done++
+				mu.Unlock()
+			})
+		}
+
+		require.NoError(t, watcher.Reload())
+		assert.Equal(t, 2, done)
+		select {
+		case err := <-watcher.Errors():
+			t.Fatalf("unexpected error: %v", err)
+		default:
+		}
+	})
+
+	t.Run("Reload error callbacks are isolated", func(t *testing.T) {
+		filePath, watcher := setup(t)
+
+		var got error
+		watcher.OnReloadError(func(err error) { panic("boom") })
+		watcher.OnReloadError(func(err error) { got = err })
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": `), 0644))
+		assert.Error(t, watcher.Reload())
+		assert.Error(t, got)
+	})
+
+	t.Run("A panicking participant vetoes the reload", func(t *testing.T) {
+		filePath, watcher := setup(t)
+
+		rolledBack := false
+		watcher.AddParticipant(config.ReloadFuncs{
+			RollbackFunc: func(next *config.Config) { rolledBack = true },
+		})
+		watcher.AddParticipant(config.ReloadFuncs{
+			PrepareFunc: func(next *config.Config) error { panic("boom") },
+		})
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "debug"}`), 0644))
+		err := watcher.Reload()
+		require.Error(t, err)
+		var callbackErr *config.CallbackError
+		assert.True(t, errors.As(err, &callbackErr))
+		assert.True(t, rolledBack)
+	})
+}
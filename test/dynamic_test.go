@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDynamic(t *testing.T) {
+	t.Run("Typed values and defaults", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"log":  map[string]interface{}{"level": "info"},
+			"rate": map[string]interface{}{"limit": 100.0, "window": "1s"},
+		})
+
+		level := config.Dynamic(cfg, "log.level", "warn")
+		limit := config.Dynamic(cfg, "rate.limit", 10)
+		window := config.Dynamic(cfg, "rate.window", time.Minute)
+		burst := config.Dynamic(cfg, "rate.burst", 5)
+
+		assert.Equal(t, "info", level.Load())
+		assert.Equal(t, 100, limit.Load())
+		assert.Equal(t, time.Second, window.Load())
+		assert.Equal(t, 5, burst.Load())
+		assert.Equal(t, "rate.burst", burst.Key())
+
+		cfg.Set("rate.limit", "250")
+		assert.Equal(t, 250, limit.Load())
+
+		cfg.SetData(map[string]interface{}{})
+		assert.Equal(t, 10, limit.Load(), "Removed keys fall back to the default")
+		assert.Equal(t, "warn", level.Load())
+	})
+
+	t.Run("Validation keeps the previous value", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.Set("rate.limit", 100)
+
+		limit := config.Dynamic(cfg, "rate.limit", 10).WithValidation(func(v int) error {
+			if v <= 0 {
+				return errors.New("must be positive")
+			}
+			return nil
+		})
+		assert.Equal(t, 100, limit.Load())
+
+		cfg.Set("rate.limit", -1)
+		assert.Equal(t, 100, limit.Load())
+		cfg.Set("rate.limit", "not a number")
+		assert.Equal(t, 100, limit.Load())
+		cfg.Set("rate.limit", 50)
+		assert.Equal(t, 50, limit.Load())
+	})
+
+	t.Run("OnChange fires only on changes", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.Set("log.level", "info")
+
+		var changes [][2]string
+		level := config.Dynamic(cfg, "log.level", "warn").OnChange(func(old, new string) {
+			changes = append(changes, [2]string{old, new})
+		})
+
+		cfg.Set("log.level", "info")
+		cfg.Set("other", true)
+		cfg.Set("log.level", "debug")
+		assert.Equal(t, "debug", level.Load())
+		assert.Equal(t, [][2]string{{"info", "debug"}}, changes)
+	})
+
+	t.Run("Struct values", func(t *testing.T) {
+		type limits struct {
+			Rate  int `config:"rate"`
+			Burst int `config:"burst"`
+		}
+		cfg := config.NewConfig()
+		cfg.Set("limits", map[string]interface{}{"rate": 5.0, "burst": 10.0})
+
+		handle := config.Dynamic(cfg, "limits", limits{})
+		assert.Equal(t, limits{Rate: 5, Burst: 10}, handle.Load())
+
+		cfg.Set("limits.burst", 20)
+		assert.Equal(t, limits{Rate: 5, Burst: 20}, handle.Load())
+	})
+
+	t.Run("Watcher reloads update handles", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"log": {"level": "info"}}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		var mu sync.Mutex
+		var seen []string
+		level := config.Dynamic(cfg, "log.level", "warn").OnChange(func(old, new string) {
+			mu.Lock()
+			seen = append(seen, new)
+			mu.Unlock()
+		})
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"log": {"level": "debug"}}`), 0644))
+
+		assert.Eventually(t, func() bool { return level.Load() == "debug" }, 2*time.Second, 10*time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, []string{"debug"}, seen)
+	})
+	t.Run("Panicking hooks do not escape reloads", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "info"}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+
+		called := false
+		level := config.Dynamic(cfg, "level", "x").
+			OnChange(func(old, new string) { panic("boom") }).
+			OnChange(func(old, new string) { called = true })
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		defer watcher.Stop()
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "debug"}`), 0644))
+		assert.NotPanics(t, func() { require.NoError(t, watcher.Reload()) })
+		assert.Equal(t, "debug", level.Load())
+		assert.True(t, called)
+
+		select {
+		case err := <-watcher.Errors():
+			var callbackErr *config.CallbackError
+			require.True(t, errors.As(err, &callbackErr), "unexpected error: %v", err)
+			assert.Equal(t, "Dynamic 'level' OnChange #1", callbackErr.Callback)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting hook error")
+		}
+
+		// Direct changes log the panic instead
+		assert.NotPanics(t, func() { cfg.Set("level", "warn") })
+		assert.Equal(t, "warn", level.Load())
+	})
+
+	t.Run("Close stops tracking", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"level": "info"})
+
+		level := config.Dynamic(cfg, "level", "warn")
+		level.Close()
+		cfg.Set("level", "debug")
+		assert.Equal(t, "info", level.Load())
+	})
+}
@@ -0,0 +1,244 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, ids ...string) *config.Keyring {
+	keyring := config.NewKeyring()
+	for _, id := range ids {
+		key, err := config.GenerateKey()
+		require.NoError(t, err)
+		require.NoError(t, keyring.AddKey(id, key))
+	}
+	return keyring
+}
+
+func TestKeyring(t *testing.T) {
+	t.Run("Encrypt and decrypt round trip", func(t *testing.T) {
+		keyring := newTestKeyring(t, "v1")
+
+		encrypted, err := keyring.Encrypt("db.password", "s3cr3t")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(encrypted, "ENC[AES256_GCM,data:"))
+		assert.True(t, config.IsEncrypted(encrypted))
+		assert.Contains(t, encrypted, "kid:v1")
+
+		decrypted, err := keyring.Decrypt("db.password", encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", decrypted)
+	})
+
+	t.Run("Values are bound to their key, type and key id", func(t *testing.T) {
+		keyring := newTestKeyring(t, "v1", "v2")
+
+		encrypted, err := keyring.Encrypt("app.banner", "low value")
+		require.NoError(t, err)
+		_, err = keyring.Decrypt("admin.password", encrypted)
+		assert.Error(t, err, "Moved to another key")
+
+		_, err = keyring.Decrypt("app.banner", strings.Replace(encrypted, "type:str", "type:int", 1))
+		assert.Error(t, err, "Edited type")
+		_, err = keyring.Decrypt("app.banner", strings.Replace(encrypted, ",kid:v1", "", 1))
+		assert.Error(t, err, "Removed key id")
+
+		data := map[string]interface{}{"admin": map[string]interface{}{"password": encrypted}}
+		err = keyring.Process(data)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "admin.password")
+
+		// List items are bound to their index
+		item, err := keyring.Encrypt("tokens[0]", "a")
+		require.NoError(t, err)
+		require.NoError(t, keyring.Process(map[string]interface{}{"tokens": []interface{}{item}}))
+		assert.Error(t, keyring.Process(map[string]interface{}{"tokens": []interface{}{"x", item}}))
+	})
+
+	t.Run("Typed values are restored", func(t *testing.T) {
+		keyring := newTestKeyring(t, "v1")
+
+		for _, value := range []interface{}{"text", int64(42), 1.5, true} {
+			encrypted, err := keyring.EncryptValue("value", value)
+			require.NoError(t, err)
+			decrypted, err := keyring.Decrypt("value", encrypted)
+			require.NoError(t, err)
+			assert.Equal(t, value, decrypted)
+		}
+
+		_, err := keyring.EncryptValue("value", []string{"x"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Key rotation", func(t *testing.T) {
+		keyring := newTestKeyring(t, "v1", "v2")
+		old, err := keyring.Encrypt("token", "old")
+		require.NoError(t, err)
+
+		require.NoError(t, keyring.SetPrimary("v2"))
+		current, err := keyring.Encrypt("token", "new")
+		require.NoError(t, err)
+		assert.Contains(t, current, "kid:v2")
+
+		value, err := keyring.Decrypt("token", old)
+		require.NoError(t, err)
+		assert.Equal(t, "old", value)
+
+		value, err = keyring.Decrypt("token", current)
+		require.NoError(t, err)
+		assert.Equal(t, "new", value)
+
+		assert.Error(t, keyring.SetPrimary("missing"))
+	})
+
+	t.Run("Invalid values and keys", func(t *testing.T) {
+		keyring := newTestKeyring(t, "v1")
+		other := newTestKeyring(t, "v1")
+
+		encrypted, err := other.Encrypt("x", "x")
+		require.NoError(t, err)
+		_, err = keyring.Decrypt("x", encrypted)
+		assert.Error(t, err)
+
+		_, err = keyring.Decrypt("x", "ENC[AES256_GCM,data:abc]")
+		assert.Error(t, err)
+		_, err = keyring.Decrypt("x", "ENC[DES,data:a,iv:b,tag:c]")
+		assert.Error(t, err)
+		_, err = keyring.Decrypt("x", strings.Replace(encrypted, "kid:v1", "kid:v9", 1))
+		assert.Error(t, err)
+
+		assert.Error(t, keyring.AddKey("short", []byte("tooshort")))
+	})
+
+	t.Run("Load keys from file and env", func(t *testing.T) {
+		k1, err := config.GenerateKey()
+		require.NoError(t, err)
+		k2, err := config.GenerateKey()
+		require.NoError(t, err)
+		b1, b2 := base64.StdEncoding.EncodeToString(k1), base64.StdEncoding.EncodeToString(k2)
+
+		path := filepath.Join(t.TempDir(), "keys.json")
+		content := fmt.Sprintf(`{"primary": "v2", "keys": {"v1": %q, "v2": %q}}`, b1, b2)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+
+		fromFile, err := config.LoadKeyringFromFile(path)
+		require.NoError(t, err)
+		encrypted, err := fromFile.Encrypt("x", "x")
+		require.NoError(t, err)
+		assert.Contains(t, encrypted, "kid:v2")
+
+		os.Setenv("GUMP_TEST_KEYRING", "v1:"+b1+",v2:"+b2)
+		defer os.Unsetenv("GUMP_TEST_KEYRING")
+
+		fromEnv, err := config.LoadKeyringFromEnv("GUMP_TEST_KEYRING")
+		require.NoError(t, err)
+		value, err := fromEnv.Decrypt("x", encrypted)
+		require.NoError(t, err)
+		assert.Equal(t, "x", value)
+
+		_, err = config.LoadKeyringFromEnv("GUMP_TEST_KEYRING_MISSING")
+		assert.Error(t, err)
+	})
+}
+
+func TestBuilderWithDecryption(t *testing.T) {
+	keyring := newTestKeyring(t, "v1")
+	password, err := keyring.Encrypt("db.password", "s3cr3t")
+	require.NoError(t, err)
+	port, err := keyring.EncryptValue("db.port", 5432)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "config.json")
+	content := fmt.Sprintf(`{"db": {"host": "localhost", "password": %q, "port": %q}}`, password, port)
+	require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+	t.Run("Values are decrypted on build", func(t *testing.T) {
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).WithDecryption(keyring).Build()
+		require.NoError(t, err)
+
+		value, err := cfg.GetString("db.password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", value)
+
+		dbPort, err := cfg.GetInt("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5432, dbPort)
+	})
+
+	t.Run("Values under deprecated keys are decrypted", func(t *testing.T) {
+		old, err := keyring.Encrypt("db.passwd_old", "legacy")
+		require.NoError(t, err)
+		nested, err := keyring.Encrypt("legacy.creds.token", "t0k3n")
+		require.NoError(t, err)
+		legacyPath := filepath.Join(dir, "legacy.json")
+		content := fmt.Sprintf(`{"db": {"passwd_old": %q}, "legacy": {"creds": {"token": %q}}}`, old, nested)
+		require.NoError(t, os.WriteFile(legacyPath, []byte(content), 0644))
+
+		cfg, err := config.NewConfigBuilder().
+			OnDeprecated(func(config.DeprecationWarning) {}).
+			WithDeprecation("db.passwd_old", "db.password", "").
+			WithAlias("legacy", "app").
+			WithJSON(legacyPath).
+			WithDecryption(keyring).
+			Build()
+		require.NoError(t, err)
+
+		value, _ := cfg.GetString("db.password")
+		assert.Equal(t, "legacy", value)
+		token, _ := cfg.GetString("app.creds.token")
+		assert.Equal(t, "t0k3n", token)
+
+		// Values still cannot move to keys that are not aliases
+		_, err = keyring.Decrypt("db.other", old)
+		assert.Error(t, err)
+	})
+
+	t.Run("Undecryptable values fail the build", func(t *testing.T) {
+		_, err := config.NewConfigBuilder().WithJSON(filePath).WithDecryption(newTestKeyring(t, "v1")).Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "db.password")
+	})
+
+	t.Run("Watcher decrypts reloaded values", func(t *testing.T) {
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).WithDecryption(keyring).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour, filePath)
+		require.NoError(t, err)
+		watcher.AddProcessor(keyring)
+
+		reloadCh := make(chan string, 1)
+		watcher.OnReload(func(c *config.Config) {
+			value, _ := c.GetString("db.password")
+			select {
+			case reloadCh <- value:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		rotated, err := keyring.Encrypt("db.password", "rotated")
+		require.NoError(t, err)
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(fmt.Sprintf(`{"db": {"password": %q}}`, rotated)), 0644))
+
+		select {
+		case value := <-reloadCh:
+			assert.Equal(t, "rotated", value)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting reload")
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// remoteConfig is a small fake config server with ETag support
+type remoteConfig struct {
+	mu           sync.Mutex
+	body         string
+	etag         string
+	contentType  string
+	cacheControl string
+	requests     int32
+	notModified  int32
+	lastAuth     string
+}
+
+func (r *remoteConfig) set(body, etag string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.body, r.etag = body, etag
+}
+
+func (r *remoteConfig) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	atomic.AddInt32(&r.requests, 1)
+	r.lastAuth = req.Header.Get("Authorization")
+
+	if r.cacheControl != "" {
+		w.Header().Set("Cache-Control", r.cacheControl)
+	}
+	if r.etag != "" && req.Header.Get("If-None-Match") == r.etag {
+		atomic.AddInt32(&r.notModified, 1)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if r.etag != "" {
+		w.Header().Set("ETag", r.etag)
+	}
+	contentType := r.contentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write([]byte(r.body))
+}
+
+func TestHTTPSource(t *testing.T) {
+	t.Run("Load JSON and revalidate with ETag", func(t *testing.T) {
+		remote := &remoteConfig{body: `{"db": {"host": "remote"}}`, etag: `"v1"`}
+		server := httptest.NewServer(remote)
+		defer server.Close()
+
+		src := config.NewHTTPSource(server.URL)
+		cfg, err := config.NewConfigBuilder().WithSource(src).Build()
+		require.NoError(t, err)
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "remote", host)
+
+		changed, err := src.Changed()
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&remote.notModified))
+
+		remote.set(`{"db": {"host": "updated"}}`, `"v2"`)
+		changed, err = src.Changed()
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		data, err := src.Load()
+		require.NoError(t, err)
+		assert.Equal(t, "updated", data["db"].(map[string]interface{})["host"])
+		assert.Equal(t, int32(3), atomic.LoadInt32(&remote.requests))
+	})
+
+	t.Run("Cache-Control max-age avoids requests", func(t *testing.T) {
+		remote := &remoteConfig{body: `{"a": 1}`, cacheControl: "max-age=60"}
+		server := httptest.NewServer(remote)
+		defer server.Close()
+
+		src := config.NewHTTPSource(server.URL)
+		_, err := src.Load()
+		require.NoError(t, err)
+		_, err = src.Load()
+		require.NoError(t, err)
+		changed, err := src.Changed()
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&remote.requests))
+	})
+
+	t.Run("Custom headers and auth", func(t *testing.T) {
+		remote := &remoteConfig{body: `{}`}
+		server := httptest.NewServer(remote)
+		defer server.Close()
+
+		_, err := config.NewHTTPSource(server.URL).WithBearerToken("abc").Load()
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer abc", remote.lastAuth)
+
+		_, err = config.NewHTTPSource(server.URL).WithBasicAuth("user", "pass").Load()
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(remote.lastAuth, "Basic "))
+	})
+
+	t.Run("Codec selected by Content-Type", func(t *testing.T) {
+		config.RegisterCodec("text/x-keyvalue", config.CodecFunc(func(data []byte) (map[string]interface{}, error) {
+			result := make(map[string]interface{})
+			for _, line := range strings.Split(string(data), "\n") {
+				if pair := strings.SplitN(line, "=", 2); len(pair) == 2 {
+					result[pair[0]] = pair[1]
+				}
+			}
+			return result, nil
+		}))
+
+		remote := &remoteConfig{body: "name=gump\nmode=dev", contentType: "text/x-keyvalue; charset=utf-8"}
+		server := httptest.NewServer(remote)
+		defer server.Close()
+
+		data, err := config.NewHTTPSource(server.URL).Load()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": "gump", "mode": "dev"}, data)
+	})
+
+	t.Run("JSON without a Content-Type", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"name": "gump"}`)) // Sniffed as text/plain
+		}))
+		defer server.Close()
+
+		data, err := config.NewHTTPSource(server.URL).Load()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"name": "gump"}, data)
+	})
+
+	t.Run("Unknown content type and bad status fail", func(t *testing.T) {
+		remote := &remoteConfig{body: `x`, contentType: "application/octet-stream"}
+		server := httptest.NewServer(remote)
+		defer server.Close()
+
+		_, err := config.NewConfigBuilder().WithSource(config.NewHTTPSource(server.URL)).Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "source load error")
+
+		missing := httptest.NewServer(http.NotFoundHandler())
+		defer missing.Close()
+		_, err = config.NewHTTPSource(missing.URL).Load()
+		assert.Error(t, err)
+	})
+
+	t.Run("Watcher polls remote changes", func(t *testing.T) {
+		remote := &remoteConfig{body: `{"feature": {"enabled": false}}`, etag: `"v1"`}
+		server := httptest.NewServer(remote)
+		defer server.Close()
+
+		src := config.NewHTTPSource(server.URL)
+		cfg, err := config.NewConfigBuilder().WithSource(src).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, 50*time.Millisecond)
+		require.NoError(t, err)
+		watcher.AddSource(src)
+
+		reloadCh := make(chan bool, 1)
+		watcher.OnReload(func(c *config.Config) {
+			select {
+			case reloadCh <- true:
+			default:
+			}
+		})
+
+		go watcher.Start()
+		defer watcher.Stop()
+
+		remote.set(`{"feature": {"enabled": true}}`, `"v2"`)
+
+		select {
+		case <-reloadCh:
+			enabled, err := cfg.GetBool("feature.enabled")
+			require.NoError(t, err)
+			assert.True(t, enabled)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting remote reload")
+		}
+	})
+}
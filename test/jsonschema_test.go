@@ -0,0 +1,209 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errorKeys collect the keys reported by a validation MultiError
+func errorKeys(t *testing.T, err error) []string {
+	multi, ok := err.(config.MultiError)
+	require.True(t, ok, "Must be a MultiError")
+
+	var keys []string
+	for _, e := range multi.Errors {
+		var keyErr *config.KeyError
+		var typeErr *config.TypeError
+		var validationErr *config.ValidationError
+		switch {
+		case errors.As(e, &keyErr):
+			keys = append(keys, keyErr.Key)
+		case errors.As(e, &typeErr):
+			keys = append(keys, typeErr.Key)
+		case errors.As(e, &validationErr):
+			keys = append(keys, validationErr.Key)
+		default:
+			t.Fatalf("unexpected error type %T", e)
+		}
+	}
+	return keys
+}
+
+func TestJSONSchema(t *testing.T) {
+	schema, err := config.LoadJSONSchema(getTestFilePath(t, "service.schema.json"))
+	require.NoError(t, err)
+
+	t.Run("Valid config", func(t *testing.T) {
+		cfg := config.NewConfig()
+		require.NoError(t, cfg.LoadFromJSON(getTestFilePath(t, "base_config.json")))
+		assert.NoError(t, schema.ValidateConfig(cfg))
+	})
+
+	t.Run("Violations point at dot-notation keys", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db": map[string]interface{}{
+				"host":  "bad host",
+				"port":  70000.0,
+				"ssl":   "maybe",
+				"hosts": "typo",
+			},
+			"app": map[string]interface{}{
+				"name":    "",
+				"version": "latest",
+			},
+			"logging": map[string]interface{}{"level": "verbose"},
+		})
+
+		err := schema.ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.ElementsMatch(t, []string{
+			"db.host", "db.hosts", "db.port", "db.ssl",
+			"app.name", "app.version", "logging.level",
+		}, errorKeys(t, err))
+	})
+
+	t.Run("Missing required keys", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"db": map[string]interface{}{"host": "x"}})
+
+		err := schema.ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.ElementsMatch(t, []string{"app", "db.port"}, errorKeys(t, err))
+	})
+
+	t.Run("Numeric strings from env vars are accepted", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db":  map[string]interface{}{"host": "x", "port": "5432", "ssl": "true"},
+			"app": map[string]interface{}{},
+		})
+		assert.NoError(t, schema.ValidateConfig(cfg))
+	})
+
+	t.Run("NaN and infinities are not numbers", func(t *testing.T) {
+		rate, err := config.ParseJSONSchema([]byte(`{"type": "object", "properties": {"rate": {"type": "number", "minimum": 1, "maximum": 10}}}`))
+		require.NoError(t, err)
+
+		cfg := config.NewConfig()
+		for _, value := range []string{"NaN", "Inf", "-infinity"} {
+			cfg.SetData(map[string]interface{}{"rate": value})
+			assert.Error(t, rate.ValidateConfig(cfg), value)
+		}
+	})
+
+	t.Run("oneOf, anyOf, items and const", func(t *testing.T) {
+		combinators, err := config.ParseJSONSchema([]byte(`{
+			"properties": {
+				"timeout": {"oneOf": [{"type": "integer"}, {"type": "string", "pattern": "^\\d+s$"}]},
+				"mode": {"anyOf": [{"const": "fast"}, {"const": "safe"}]},
+				"servers": {"type": "array", "minItems": 1, "items": {"type": "object", "required": ["host"]}},
+				"ratio": {"type": "number", "exclusiveMaximum": 1, "multipleOf": 0.25}
+			}
+		}`))
+		require.NoError(t, err)
+
+		valid := config.NewConfig()
+		valid.SetData(map[string]interface{}{
+			"timeout": "30s",
+			"mode":    "safe",
+			"servers": []interface{}{map[string]interface{}{"host": "a"}},
+			"ratio":   0.5,
+		})
+		assert.NoError(t, combinators.ValidateConfig(valid))
+
+		invalid := config.NewConfig()
+		invalid.SetData(map[string]interface{}{
+			"timeout": true,
+			"mode":    "yolo",
+			"servers": []interface{}{map[string]interface{}{"port": 1.0}},
+			"ratio":   1.0,
+		})
+		err = combinators.ValidateConfig(invalid)
+		require.Error(t, err)
+		assert.ElementsMatch(t, []string{"timeout", "mode", "servers[0].host", "ratio"}, errorKeys(t, err))
+	})
+
+	t.Run("Invalid schemas", func(t *testing.T) {
+		_, err := config.ParseJSONSchema([]byte(`{`))
+		assert.Error(t, err)
+
+		_, err = config.LoadJSONSchema("nonexistent.schema.json")
+		assert.Error(t, err)
+
+		badRef, err := config.ParseJSONSchema([]byte(`{"properties": {"a": {"$ref": "#/$defs/missing"}}}`))
+		require.NoError(t, err)
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"a": 1.0})
+		assert.Error(t, badRef.ValidateConfig(cfg))
+	})
+}
+
+func TestJSONSchemaIntegration(t *testing.T) {
+	schema, err := config.LoadJSONSchema(getTestFilePath(t, "service.schema.json"))
+	require.NoError(t, err)
+
+	t.Run("Builder validates on Build", func(t *testing.T) {
+		_, err := config.NewConfigBuilder().
+			WithJSON(getTestFilePath(t, "base_config.json")).
+			WithJSONSchema(schema).
+			Build()
+		require.NoError(t, err)
+
+		_, err = config.NewConfigBuilder().
+			WithJSON(getTestFilePath(t, "base_config.json")).
+			WithOverrides([]string{"db.port=0"}).
+			WithJSONSchema(schema).
+			Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "db.port")
+	})
+
+	t.Run("Watcher rejects invalid reloads", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "a", "port": 1}, "app": {}}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).WithJSONSchema(schema).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour, filePath)
+		require.NoError(t, err)
+		watcher.AddValidator(schema)
+
+		reloadCh := make(chan int, 1)
+		watcher.OnReload(func(c *config.Config) {
+			port, _ := c.GetInt("db.port")
+			select {
+			case reloadCh <- port:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "a", "port": 0}, "app": {}}`), 0644))
+
+		select {
+		case <-reloadCh:
+			t.Fatal("Invalid config must not be applied")
+		case <-time.After(300 * time.Millisecond):
+		}
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"host": "a", "port": 2}, "app": {}}`), 0644))
+		select {
+		case port := <-reloadCh:
+			assert.Equal(t, 2, port)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting valid reload")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryKV(t *testing.T) {
+	t.Run("Put, Get, List and Delete", func(t *testing.T) {
+		kv := config.NewMemoryKV()
+		assert.Equal(t, int64(1), kv.Put("app/db/host", "localhost"))
+		assert.Equal(t, int64(2), kv.Put("app/db/port", "5432"))
+		kv.Put("other/key", "x")
+
+		pair, err := kv.Get(context.Background(), "app/db/host")
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", string(pair.Value))
+		assert.Equal(t, int64(1), pair.Revision)
+
+		pairs, revision, err := kv.List(context.Background(), "app/")
+		require.NoError(t, err)
+		assert.Len(t, pairs, 2)
+		assert.Equal(t, int64(3), revision)
+
+		kv.Delete("app/db/host")
+		_, err = kv.Get(context.Background(), "app/db/host")
+		var keyErr *config.KeyError
+		assert.ErrorAs(t, err, &keyErr)
+	})
+
+	t.Run("Watch replays and streams events", func(t *testing.T) {
+		kv := config.NewMemoryKV()
+		kv.Put("app/a", "1")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		events, err := kv.Watch(ctx, "app/", 1)
+		require.NoError(t, err)
+
+		kv.Put("other/b", "ignored")
+		kv.Delete("app/a")
+
+		first := <-events
+		assert.Equal(t, config.KVPut, first.Type)
+		assert.Equal(t, int64(1), first.Revision)
+
+		second := <-events
+		assert.Equal(t, config.KVDelete, second.Type)
+		assert.Equal(t, "app/a", second.Pair.Key)
+
+		cancel()
+		select {
+		case _, ok := <-events:
+			assert.False(t, ok)
+		case <-time.After(time.Second):
+			t.Fatal("Watch channel not closed after cancel")
+		}
+	})
+}
+
+func TestKVSource(t *testing.T) {
+	t.Run("Flat paths map to nested keys", func(t *testing.T) {
+		kv := config.NewMemoryKV()
+		kv.Put("services/api/db/host", "db.internal")
+		kv.Put("services/api/db/port", "5432")
+		kv.Put("services/api/features", `["a","b"]`)
+		kv.Put("services/web/db/host", "ignored")
+
+		src := config.NewKVSource(kv, "services/api/").WithJSONValues()
+		defer src.Close()
+
+		cfg, err := config.NewConfigBuilder().WithSource(src).Build()
+		require.NoError(t, err)
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "db.internal", host)
+
+		port, err := cfg.GetInt("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5432, port)
+
+		features, err := cfg.GetValue("features")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", "b"}, features)
+
+		_, err = cfg.GetValue("web")
+		assert.Error(t, err)
+	})
+
+	t.Run("Custom separator", func(t *testing.T) {
+		kv := config.NewMemoryKV()
+		kv.Put("app:db:host", "x")
+
+		src := config.NewKVSource(kv, "app").WithSeparator(":")
+		defer src.Close()
+
+		data, err := src.Load()
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"db": map[string]interface{}{"host": "x"}}, data)
+	})
+
+	t.Run("Changes detected through watch", func(t *testing.T) {
+		kv := config.NewMemoryKV()
+		kv.Put("app/level", "info")
+
+		src := config.NewKVSource(kv, "app/")
+		defer src.Close()
+
+		_, err := src.Load()
+		require.NoError(t, err)
+
+		changed, err := src.Changed()
+		require.NoError(t, err)
+		assert.False(t, changed)
+
+		kv.Put("app/level", "debug")
+		assert.Eventually(t, func() bool {
+			changed, err := src.Changed()
+			return err == nil && changed
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Watcher reloads on store changes", func(t *testing.T) {
+		kv := config.NewMemoryKV()
+		kv.Put("app/level", "info")
+
+		src := config.NewKVSource(kv, "app/")
+		defer src.Close()
+
+		cfg, err := config.NewConfigBuilder().WithSource(src).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, 20*time.Millisecond)
+		require.NoError(t, err)
+		watcher.AddSource(src)
+
+		reloadCh := make(chan string, 1)
+		watcher.OnReload(func(c *config.Config) {
+			level, _ := c.GetString("level")
+			select {
+			case reloadCh <- level:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		kv.Put("app/level", "debug")
+
+		select {
+		case level := <-reloadCh:
+			assert.Equal(t, "debug", level)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting KV reload")
+		}
+	})
+}
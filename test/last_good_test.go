@@ -0,0 +1,174 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastKnownGood(t *testing.T) {
+	portRule := config.ConfigValidatorFunc(func(c *config.Config) error {
+		if port, err := c.GetInt("db.port"); err != nil || port <= 0 {
+			return errors.New("db.port must be positive")
+		}
+		return nil
+	})
+
+	t.Run("Invalid reloads keep the last good config", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "config.json")
+		snapshotPath := filepath.Join(dir, "last_good.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": 1}}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().
+			WithJSON(filePath).
+			WithValidator(portRule).
+			WithLastGood(snapshotPath).
+			Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		startedAt := watcher.LastGoodAt()
+		assert.NoError(t, watcher.LastError())
+
+		errCh := make(chan error, 4)
+		reloadCh := make(chan bool, 4)
+		watcher.OnReloadError(func(err error) { errCh <- err })
+		watcher.OnReload(func(c *config.Config) { reloadCh <- true })
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": 0}}`), 0644))
+
+		// A truncated write may fail to decode first; wait for the validation error
+		for validated := false; !validated; {
+			select {
+			case err := <-errCh:
+				validated = strings.Contains(err.Error(), "db.port must be positive")
+			case <-reloadCh:
+				t.Fatal("Invalid config must not be applied")
+			case <-time.After(2 * time.Second):
+				t.Fatal("Timeout waiting reload error")
+			}
+		}
+
+		port, _ := cfg.GetInt("db.port")
+		assert.Equal(t, 1, port)
+		assert.Error(t, watcher.LastError())
+		assert.Equal(t, startedAt, watcher.LastGoodAt())
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": 2}}`), 0644))
+		select {
+		case <-reloadCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting valid reload")
+		}
+		assert.NoError(t, watcher.LastError())
+		assert.True(t, watcher.LastGoodAt().After(startedAt))
+
+		saved, err := config.LoadLastGood(snapshotPath)
+		require.NoError(t, err)
+		port, _ = saved.GetInt("db.port")
+		assert.Equal(t, 2, port)
+
+		info, err := os.Stat(snapshotPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("Build falls back to the last good config", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "config.json")
+		snapshotPath := filepath.Join(dir, "last_good.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": 7}}`), 0644))
+
+		builder := func() *config.ConfigBuilder {
+			return config.NewConfigBuilder().WithJSON(filePath).WithValidator(portRule).WithLastGood(snapshotPath)
+		}
+		_, err := builder().Build()
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": -1}}`), 0644))
+		cfg, err := builder().Build()
+		require.NoError(t, err)
+		port, _ := cfg.GetInt("db.port")
+		assert.Equal(t, 7, port)
+
+		// Without a snapshot the build error is returned
+		_, err = config.NewConfigBuilder().
+			WithJSON(filePath).
+			WithValidator(portRule).
+			WithLastGood(filepath.Join(dir, "missing.json")).
+			Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("Persist configs not built by a builder", func(t *testing.T) {
+		dir := t.TempDir()
+		cfg := config.NewConfig()
+		cfg.Set("app.name", "GUMP")
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		defer watcher.Stop()
+
+		snapshotPath := filepath.Join(dir, "snapshot.json")
+		require.NoError(t, watcher.PersistLastGood(snapshotPath))
+
+		saved, err := config.LoadLastGood(snapshotPath)
+		require.NoError(t, err)
+		name, _ := saved.GetString("app.name")
+		assert.Equal(t, "GUMP", name)
+	})
+	t.Run("Secrets are not written to disk", func(t *testing.T) {
+		dir := t.TempDir()
+		secretsPath := filepath.Join(dir, "secrets.json")
+		require.NoError(t, os.WriteFile(secretsPath, []byte(`{"db/main": {"password": "hunter2"}}`), 0600))
+		filePath := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": 1, "password": "secret://db/main#password"}}`), 0644))
+		snapshotPath := filepath.Join(dir, "last_good.json")
+
+		builder := func() *config.ConfigBuilder {
+			manager := config.NewSecretManager(config.NewFileSecretResolver(secretsPath))
+			return config.NewConfigBuilder().
+				WithJSON(filePath).
+				WithSecrets(manager).
+				WithValidator(portRule).
+				WithLastGood(snapshotPath)
+		}
+		cfg, err := builder().Build()
+		require.NoError(t, err)
+		password, _ := cfg.GetString("db.password")
+		assert.Equal(t, "hunter2", password)
+
+		content, err := os.ReadFile(snapshotPath)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "hunter2")
+		assert.Contains(t, string(content), "secret://db/main#password")
+
+		// Direct changes are kept in the saved tree
+		cfg.Set("db.port", 2)
+		require.NoError(t, config.SaveLastGood(cfg, snapshotPath))
+		content, _ = os.ReadFile(snapshotPath)
+		assert.NotContains(t, string(content), "hunter2")
+
+		// The fallback resolves the references again
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"db": {"port": -1}}`), 0644))
+		cfg, err = builder().Build()
+		require.NoError(t, err)
+		password, _ = cfg.GetString("db.password")
+		assert.Equal(t, "hunter2", password)
+		port, _ := cfg.GetInt("db.port")
+		assert.Equal(t, 2, port)
+	})
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatcherLifecycle(t *testing.T) {
+	t.Run("Run returns when the context is cancelled", func(t *testing.T) {
+		watcher, err := config.NewConfigWatcher(config.NewConfig(), time.Hour)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- watcher.Run(ctx) }()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.ErrorIs(t, err, context.Canceled)
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return after cancel")
+		}
+
+		assert.ErrorIs(t, watcher.Run(context.Background()), config.ErrWatcherStarted)
+		watcher.Stop()
+	})
+
+	t.Run("Stop is idempotent and waits for the loop", func(t *testing.T) {
+		watcher, err := config.NewConfigWatcher(config.NewConfig(), time.Hour)
+		require.NoError(t, err)
+
+		var exited sync.WaitGroup
+		exited.Add(1)
+		returned := make(chan error, 1)
+		go func() {
+			defer exited.Done()
+			returned <- watcher.Run(context.Background())
+		}()
+		time.Sleep(50 * time.Millisecond)
+
+		watcher.Stop()
+		select {
+		case err := <-returned:
+			assert.NoError(t, err)
+		default:
+			t.Fatal("Stop must wait for the loop to exit")
+		}
+		assert.NotPanics(t, watcher.Stop)
+		exited.Wait()
+
+		// Stopping a watcher that never ran does not block
+		idle, err := config.NewConfigWatcher(config.NewConfig(), time.Hour)
+		require.NoError(t, err)
+		idle.Stop()
+		idle.Stop()
+	})
+
+	t.Run("Errors channel and logger", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"a": 1}`), 0644))
+
+		var logs syncBuffer
+		logger := slog.New(slog.NewTextHandler(&logs, nil))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).WithLogger(logger).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+		assert.Same(t, logger, cfg.Logger())
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		watcher.SetDebounce(10 * time.Millisecond)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go watcher.Run(ctx)
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"a": `), 0644))
+
+		select {
+		case err := <-watcher.Errors():
+			assert.Contains(t, err.Error(), "reload error")
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting reload error")
+		}
+		assert.Contains(t, logs.String(), "config watcher error")
+	})
+
+	t.Run("Errors channel is closed on stop", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"a": 1}`), 0644))
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		watcher.SetLogger(slog.New(slog.NewTextHandler(&syncBuffer{}, nil)))
+		go watcher.Run(context.Background())
+
+		drained := make(chan int)
+		go func() {
+			count := 0
+			for range watcher.Errors() {
+				count++
+			}
+			drained <- count
+		}()
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"a": `), 0644))
+		assert.Error(t, watcher.Reload())
+		watcher.Stop()
+
+		select {
+		case count := <-drained:
+			assert.Equal(t, 1, count)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Errors channel not closed after Stop")
+		}
+
+		// Errors reported once stopped are only logged
+		assert.NotPanics(t, func() { _ = watcher.Reload() })
+
+		idle, err := config.NewConfigWatcher(config.NewConfig(), time.Hour)
+		require.NoError(t, err)
+		idle.Stop()
+		_, open := <-idle.Errors()
+		assert.False(t, open)
+	})
+
+	t.Run("Warnings use the config logger", func(t *testing.T) {
+		var logs syncBuffer
+		cfg := config.NewConfig()
+		cfg.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+		cfg.SetData(map[string]interface{}{"db": map[string]interface{}{"hots": "x"}})
+
+		strict := config.NewStrictChecker(config.StrictWarn).WithKeys("db.host")
+		require.NoError(t, strict.ValidateConfig(cfg))
+		assert.Contains(t, logs.String(), "unknown config key")
+		assert.Contains(t, logs.String(), "suggestion=db.host")
+
+		cfg.Deprecate("db.hots", "db.host", "")
+		assert.Contains(t, logs.String(), "deprecated config key")
+	})
+}
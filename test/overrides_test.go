@@ -0,0 +1,193 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOverrides(t *testing.T) {
+	t.Run("Typed literal inference", func(t *testing.T) {
+		cfg, err := config.ParseOverrides([]string{
+			"db.port=5433",
+			"db.ssl=true",
+			"ratio=0.5",
+			"db.host=example.com",
+			"empty=null",
+			"zip=007",
+			"offset=-007",
+			"delta=-7",
+		})
+		require.NoError(t, err)
+
+		port, err := cfg.GetValue("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, int64(5433), port)
+
+		ssl, err := cfg.GetValue("db.ssl")
+		require.NoError(t, err)
+		assert.Equal(t, true, ssl)
+
+		ratio, err := cfg.GetValue("ratio")
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, ratio)
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "example.com", host)
+
+		empty, err := cfg.GetValue("empty")
+		require.NoError(t, err)
+		assert.Nil(t, empty)
+
+		zip, err := cfg.GetValue("zip")
+		require.NoError(t, err)
+		assert.Equal(t, "007", zip)
+
+		offset, err := cfg.GetValue("offset")
+		require.NoError(t, err)
+		assert.Equal(t, "-007", offset)
+
+		delta, err := cfg.GetValue("delta")
+		require.NoError(t, err)
+		assert.Equal(t, int64(-7), delta)
+	})
+
+	t.Run("Multiple assignments and escapes", func(t *testing.T) {
+		cfg, err := config.ParseOverrides([]string{`a=1,b=x\,y`, `labels.app\.kubernetes\.io/name=gump`})
+		require.NoError(t, err)
+
+		b, err := cfg.GetString("b")
+		require.NoError(t, err)
+		assert.Equal(t, "x,y", b)
+
+		labels, err := cfg.GetValue("labels")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]interface{}{"app.kubernetes.io/name": "gump"}, labels)
+	})
+
+	t.Run("Lists and indexes", func(t *testing.T) {
+		cfg, err := config.ParseOverrides([]string{
+			"tags={a,b,3}",
+			"servers[1].host=x",
+			"matrix[0][1]=2",
+		})
+		require.NoError(t, err)
+
+		tags, err := cfg.GetValue("tags")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"a", "b", int64(3)}, tags)
+
+		servers, err := cfg.GetValue("servers")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{nil, map[string]interface{}{"host": "x"}}, servers)
+
+		matrix, err := cfg.GetValue("matrix")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{[]interface{}{nil, int64(2)}}, matrix)
+	})
+
+	t.Run("String overrides", func(t *testing.T) {
+		cfg, err := config.ParseStringOverrides([]string{"db.port=5433", "flag=true"})
+		require.NoError(t, err)
+
+		port, err := cfg.GetValue("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, "5433", port)
+
+		flag, err := cfg.GetValue("flag")
+		require.NoError(t, err)
+		assert.Equal(t, "true", flag)
+	})
+
+	t.Run("File overrides", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cert.pem")
+		require.NoError(t, os.WriteFile(path, []byte("CERT"), 0644))
+
+		cfg, err := config.ParseFileOverrides([]string{"tls.cert=" + path})
+		require.NoError(t, err)
+
+		cert, err := cfg.GetString("tls.cert")
+		require.NoError(t, err)
+		assert.Equal(t, "CERT", cert)
+
+		_, err = config.ParseFileOverrides([]string{"tls.cert=/nonexistent/cert.pem"})
+		assert.Error(t, err)
+	})
+
+	t.Run("Invalid overrides", func(t *testing.T) {
+		for _, value := range []string{"novalue", "a..b=1", "[0]=1", "a[x]=1", "a[0=1", "=1", "a[0]x=1", "b[1]]=2", "c[0]d.e=1"} {
+			_, err := config.ParseOverrides([]string{value})
+			assert.Error(t, err, value)
+		}
+	})
+
+	t.Run("List indexes are bounded", func(t *testing.T) {
+		_, err := config.ParseOverrides([]string{"servers[2000000000]=x"})
+		assert.ErrorContains(t, err, "exceeds the maximum of 65536")
+
+		cfg, err := config.ParseOverrides([]string{"servers[65536]=x"})
+		require.NoError(t, err)
+		servers, _ := cfg.GetValue("servers")
+		assert.Len(t, servers, 65537)
+	})
+}
+
+func TestBuilderWithOverrides(t *testing.T) {
+	basePath := getTestFilePath(t, "base_config.json")
+
+	t.Run("Overrides have precedence over previous sources", func(t *testing.T) {
+		cfg, err := config.NewConfigBuilder().
+			WithJSON(basePath).
+			WithOverrides([]string{"db.port=5433", "db.host=override"}).
+			Build()
+		require.NoError(t, err)
+
+		port, err := cfg.GetInt("db.port")
+		require.NoError(t, err)
+		assert.Equal(t, 5433, port)
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "override", host)
+
+		name, err := cfg.GetString("app.name")
+		require.NoError(t, err)
+		assert.Equal(t, "GUMP App", name)
+	})
+
+	t.Run("Indexes update existing lists", func(t *testing.T) {
+		base := config.NewConfig()
+		base.SetData(map[string]interface{}{
+			"servers": []interface{}{
+				map[string]interface{}{"host": "a", "port": 80.0},
+				map[string]interface{}{"host": "b", "port": 81.0},
+			},
+		})
+
+		cfg, err := config.NewConfigBuilder().
+			WithConfig(base).
+			WithOverrides([]string{"servers[0].host=x"}).
+			Build()
+		require.NoError(t, err)
+
+		servers, err := cfg.GetValue("servers")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"host": "x", "port": 80.0},
+			map[string]interface{}{"host": "b", "port": 81.0},
+		}, servers)
+	})
+
+	t.Run("Invalid override is reported on build", func(t *testing.T) {
+		_, err := config.NewConfigBuilder().
+			WithStringOverrides([]string{"broken"}).
+			Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "override error")
+	})
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContentHashPolling(t *testing.T) {
+	setup := func(t *testing.T, content string) (string, *config.Config) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+		return filePath, cfg
+	}
+
+	t.Run("Touching a file without changes does not reload", func(t *testing.T) {
+		filePath, cfg := setup(t, `{"level": "info"}`)
+
+		watcher, err := config.NewConfigWatcher(cfg, 20*time.Millisecond)
+		require.NoError(t, err)
+		var reloads atomic.Int32
+		watcher.OnReload(func(c *config.Config) { reloads.Add(1) })
+		go watcher.Start()
+		defer watcher.Stop()
+
+		future := time.Now().Add(time.Hour)
+		require.NoError(t, os.Chtimes(filePath, future, future))
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "info"}`), 0644))
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, int32(0), reloads.Load())
+	})
+
+	t.Run("Changes with an old modification time are detected", func(t *testing.T) {
+		filePath, cfg := setup(t, `{"level": "info"}`)
+		cfg.LastModified = time.Now().Add(time.Hour) // Clock skew
+
+		watcher, err := config.NewConfigWatcher(cfg, 20*time.Millisecond)
+		require.NoError(t, err)
+		watcher.SetPollingOnly(true)
+		go watcher.Start()
+		defer watcher.Stop()
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "debug"}`), 0644))
+		past := time.Now().Add(-time.Hour)
+		require.NoError(t, os.Chtimes(filePath, past, past))
+
+		assert.Eventually(t, func() bool {
+			level, _ := cfg.GetString("level")
+			return level == "debug"
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Polling-only mode reloads files and source directories", func(t *testing.T) {
+		filePath, _ := setup(t, `{"level": "info"}`)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "user"), []byte("admin"), 0644))
+
+		cfg, err := config.NewConfigBuilder().
+			WithJSON(filePath).
+			WithSource(config.NewDirSource(dir)).
+			Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, 20*time.Millisecond)
+		require.NoError(t, err)
+		watcher.SetPollingOnly(true)
+		watcher.SetDebounce(time.Hour) // Notifications would never fire
+		go watcher.Start()
+		defer watcher.Stop()
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "warn"}`), 0644))
+		assert.Eventually(t, func() bool {
+			level, _ := cfg.GetString("level")
+			return level == "warn"
+		}, 2*time.Second, 10*time.Millisecond)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "user"), []byte("root"), 0644))
+		assert.Eventually(t, func() bool {
+			user, _ := cfg.GetString("user")
+			return user == "root"
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("Adding an unchanged source does not reload", func(t *testing.T) {
+		_, cfg := setup(t, `{"level": "info"}`)
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "user"), []byte("admin"), 0644))
+
+		watcher, err := config.NewConfigWatcher(cfg, 20*time.Millisecond)
+		require.NoError(t, err)
+		watcher.SetPollingOnly(true)
+		watcher.AddSource(config.NewDirSource(dir))
+		var reloads atomic.Int32
+		watcher.OnReload(func(c *config.Config) { reloads.Add(1) })
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(200 * time.Millisecond)
+		assert.Equal(t, int32(0), reloads.Load())
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "user"), []byte("root"), 0644))
+		assert.Eventually(t, func() bool {
+			user, _ := cfg.GetString("user")
+			return user == "root"
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("A broken file is not retried every tick", func(t *testing.T) {
+		filePath, cfg := setup(t, `{"level": "info"}`)
+
+		watcher, err := config.NewConfigWatcher(cfg, 20*time.Millisecond)
+		require.NoError(t, err)
+		watcher.SetPollingOnly(true)
+		var failures atomic.Int32
+		watcher.OnReloadError(func(err error) { failures.Add(1) })
+		go watcher.Start()
+		defer watcher.Stop()
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": `), 0644))
+		assert.Eventually(t, func() bool { return failures.Load() == 1 }, 2*time.Second, 10*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		assert.Equal(t, int32(1), failures.Load())
+	})
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleSet(t *testing.T) {
+	newRules := func() *config.RuleSet {
+		rules := config.NewRuleSet()
+		rules.When("tls.enabled", true).Required("tls.cert", "tls.key")
+		rules.LessOrEqual("pool.min", "pool.max")
+		rules.Check("distinct-ports", []string{"http.port", "admin.port"}, func(v ...interface{}) bool {
+			return v[0] != v[1]
+		}, "ports must differ")
+		return rules
+	}
+
+	t.Run("Valid config", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"tls":   map[string]interface{}{"enabled": true, "cert": "c.pem", "key": "k.pem"},
+			"pool":  map[string]interface{}{"min": 2.0, "max": "10"},
+			"http":  map[string]interface{}{"port": 80.0},
+			"admin": map[string]interface{}{"port": 81.0},
+		})
+		assert.NoError(t, cfg.ValidateRules(newRules()))
+	})
+
+	t.Run("Conditions not met are skipped", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"tls": map[string]interface{}{"enabled": "false"},
+		})
+		assert.NoError(t, newRules().ValidateConfig(cfg))
+	})
+
+	t.Run("Every violation is reported", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"tls":   map[string]interface{}{"enabled": "true"},
+			"pool":  map[string]interface{}{"min": 20.0, "max": 10.0},
+			"http":  map[string]interface{}{"port": 80.0},
+			"admin": map[string]interface{}{"port": 80.0},
+		})
+
+		err := newRules().ValidateConfig(cfg)
+		require.Error(t, err)
+		multi := err.(config.MultiError)
+		require.Len(t, multi.Errors, 4)
+
+		assert.Equal(t, "invalid value for key 'tls.cert': required when tls.enabled is true", multi.Errors[0].Error())
+		assert.Equal(t, "invalid value for key 'tls.key': required when tls.enabled is true", multi.Errors[1].Error())
+
+		var ruleErr *config.RuleError
+		require.True(t, errors.As(multi.Errors[2], &ruleErr))
+		assert.Equal(t, []string{"pool.min", "pool.max"}, ruleErr.Keys)
+		require.True(t, errors.As(multi.Errors[3], &ruleErr))
+		assert.Equal(t, "distinct-ports", ruleErr.Rule)
+		assert.Equal(t, "rule 'distinct-ports' failed for keys [http.port, admin.port]: ports must differ", ruleErr.Error())
+	})
+
+	t.Run("Non-numeric comparisons", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"pool": map[string]interface{}{"min": "few", "max": 10.0}})
+
+		err := config.NewRuleSet().LessThan("pool.min", "pool.max").ValidateConfig(cfg)
+		require.Error(t, err)
+		var typeErr *config.TypeError
+		require.True(t, errors.As(err, &typeErr))
+		assert.Equal(t, "pool.min", typeErr.Key)
+	})
+
+	t.Run("Unconditional and custom conditions", func(t *testing.T) {
+		rules := config.NewRuleSet().Required("app.name")
+		rules.WhenPresent("db.replica").Required("db.replica_port")
+		rules.WhenFunc("mode is not dev", func(c *config.Config) bool {
+			mode, _ := c.GetString("mode")
+			return mode != "dev"
+		}).Required("auth.issuer")
+
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db":   map[string]interface{}{"replica": "r1"},
+			"mode": "prod",
+		})
+
+		err := rules.ValidateConfig(cfg)
+		require.Error(t, err)
+		multi := err.(config.MultiError)
+		require.Len(t, multi.Errors, 3)
+		_, isKeyErr := multi.Errors[0].(*config.KeyError)
+		assert.True(t, isKeyErr)
+		assert.Contains(t, multi.Errors[1].Error(), "required when db.replica is set")
+		assert.Contains(t, multi.Errors[2].Error(), "required when mode is not dev")
+	})
+
+	t.Run("Builder evaluates rules after merge", func(t *testing.T) {
+		rules := config.NewRuleSet()
+		rules.LessOrEqual("pool.min", "pool.max")
+
+		_, err := config.NewConfigBuilder().
+			WithOverrides([]string{"pool.min=5", "pool.max=10"}).
+			WithRules(rules).
+			Build()
+		require.NoError(t, err)
+
+		_, err = config.NewConfigBuilder().
+			WithOverrides([]string{"pool.min=5", "pool.max=10", "pool.min=50"}).
+			WithRules(rules).
+			Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "pool.min <= pool.max")
+	})
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newServiceSchema() *config.Schema {
+	schema := config.NewSchema()
+	schema.String("db.host").Required().Format(config.FormatHostname)
+	schema.Int("db.port").Required().Min(1).Max(65535)
+	schema.String("db.name").MinLen(1).MaxLen(8)
+	schema.String("log.level").Default("info").Enum("debug", "info", "warn", "error")
+	schema.String("app.version").Pattern(`^v?\d+\.\d+(\.\d+)?$`)
+	schema.String("app.url").Format(config.FormatURL)
+	schema.Duration("http.timeout").Default("5s")
+	schema.Bool("debug")
+	schema.List("servers").MaxLen(2)
+	return schema
+}
+
+func TestValidateSchema(t *testing.T) {
+	t.Run("Valid config", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db":      map[string]interface{}{"host": "db.internal", "port": 5432.0, "name": "main"},
+			"log":     map[string]interface{}{"level": "debug"},
+			"app":     map[string]interface{}{"version": "v1.2", "url": "https://example.com/api"},
+			"http":    map[string]interface{}{"timeout": "1m30s"},
+			"debug":   "true",
+			"servers": []interface{}{"a", "b"},
+		})
+		assert.NoError(t, cfg.ValidateSchema(newServiceSchema()))
+	})
+
+	t.Run("Every violation is reported", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db":      map[string]interface{}{"host": "bad_host!", "port": 70000.0, "name": "a-very-long-name"},
+			"log":     map[string]interface{}{"level": "verbose"},
+			"app":     map[string]interface{}{"version": "latest", "url": "not a url"},
+			"http":    map[string]interface{}{"timeout": "soon"},
+			"debug":   "maybe",
+			"servers": []interface{}{"a", "b", "c"},
+		})
+
+		err := cfg.ValidateSchema(newServiceSchema())
+		require.Error(t, err)
+
+		multi, ok := err.(config.MultiError)
+		require.True(t, ok, "Must be a MultiError")
+
+		keys := make([]string, 0, len(multi.Errors))
+		for _, e := range multi.Errors {
+			var validationErr *config.ValidationError
+			var typeErr *config.TypeError
+			switch {
+			case errors.As(e, &validationErr):
+				keys = append(keys, validationErr.Key)
+			case errors.As(e, &typeErr):
+				keys = append(keys, typeErr.Key)
+			default:
+				t.Fatalf("unexpected error type %T", e)
+			}
+		}
+		assert.Equal(t, []string{
+			"db.host", "db.port", "db.name", "log.level",
+			"app.version", "app.url", "http.timeout", "debug", "servers",
+		}, keys)
+	})
+
+	t.Run("Missing required keys", func(t *testing.T) {
+		cfg := config.NewConfig()
+		err := cfg.ValidateSchema(newServiceSchema())
+		require.Error(t, err)
+
+		var keyErr *config.KeyError
+		require.ErrorAs(t, err, &keyErr)
+		assert.Equal(t, "db.host", keyErr.Key)
+		assert.Len(t, err.(config.MultiError).Errors, 2)
+	})
+
+	t.Run("Type errors", func(t *testing.T) {
+		schema := config.NewSchema()
+		schema.Int("port")
+		schema.String("name")
+		schema.Map("db")
+
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"port": 80.5, "name": 5.0, "db": "x"})
+
+		err := cfg.ValidateSchema(schema)
+		require.Error(t, err)
+		multi := err.(config.MultiError)
+		require.Len(t, multi.Errors, 3)
+		for _, e := range multi.Errors {
+			assert.IsType(t, &config.TypeError{}, e)
+		}
+
+		// Numeric strings from env vars are accepted as ints
+		cfg.SetData(map[string]interface{}{"port": "8080"})
+		assert.NoError(t, cfg.ValidateSchema(schema))
+	})
+
+	t.Run("NaN and infinities are not numbers", func(t *testing.T) {
+		schema := config.NewSchema()
+		schema.Float("rate").Min(1).Max(10)
+
+		cfg := config.NewConfig()
+		for _, value := range []interface{}{"NaN", "Inf", "-infinity", math.NaN(), math.Inf(1)} {
+			cfg.SetData(map[string]interface{}{"rate": value})
+			assert.Error(t, cfg.ValidateSchema(schema), "%v", value)
+		}
+	})
+
+	t.Run("Port format and invalid pattern", func(t *testing.T) {
+		schema := config.NewSchema()
+		schema.Field("port", config.TypeAny).Format(config.FormatPort)
+		schema.String("name").Pattern("(")
+
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"port": "0", "name": "x"})
+
+		err := cfg.ValidateSchema(schema)
+		require.Error(t, err)
+		assert.Len(t, err.(config.MultiError).Errors, 2)
+	})
+
+	t.Run("Config satisfies the SchemaValidator interface", func(t *testing.T) {
+		var validator config.SchemaValidator = config.NewConfig()
+		assert.NoError(t, validator.ValidateSchema(config.NewSchema()))
+	})
+}
+
+func TestBuilderWithSchema(t *testing.T) {
+	basePath := getTestFilePath(t, "base_config.json")
+
+	t.Run("Defaults are applied", func(t *testing.T) {
+		cfg, err := config.NewConfigBuilder().
+			WithJSON(basePath).
+			WithSchema(newServiceSchema()).
+			Build()
+		require.NoError(t, err)
+
+		level, err := cfg.GetString("log.level")
+		require.NoError(t, err)
+		assert.Equal(t, "info", level)
+
+		timeout, err := cfg.GetString("http.timeout")
+		require.NoError(t, err)
+		assert.Equal(t, "5s", timeout)
+	})
+
+	t.Run("Violations fail the build", func(t *testing.T) {
+		_, err := config.NewConfigBuilder().
+			WithJSON(basePath).
+			WithOverrides([]string{"db.port=0"}).
+			WithSchema(newServiceSchema()).
+			Build()
+		require.Error(t, err)
+
+		var validationErr *config.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, "db.port", validationErr.Key)
+		assert.Equal(t, "invalid value for key 'db.port': must be >= 1", validationErr.Error())
+	})
+}
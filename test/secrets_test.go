@@ -0,0 +1,235 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leasedResolver hand out renewable secrets and count calls; like Vault it
+// refuses to renew a lease past its TTL
+type leasedResolver struct {
+	mu        sync.Mutex
+	value     string
+	ttl       time.Duration
+	leasedAt  time.Time
+	resolves  int
+	renews    int
+	failRenew bool
+}
+
+func (r *leasedResolver) Resolve(ref config.SecretRef) (config.Secret, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resolves++
+	r.leasedAt = time.Now()
+	return config.Secret{Value: r.value, TTL: r.ttl, LeaseID: "lease-1", Renewable: true}, nil
+}
+
+func (r *leasedResolver) Renew(ref config.SecretRef, secret config.Secret) (config.Secret, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failRenew || time.Since(r.leasedAt) >= r.ttl {
+		return config.Secret{}, errors.New("lease expired")
+	}
+	r.renews++
+	r.leasedAt = time.Now()
+	secret.Value = r.value
+	return secret, nil
+}
+
+func writeSecrets(t *testing.T, path, content string) {
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func TestParseSecretRef(t *testing.T) {
+	ref, ok := config.ParseSecretRef("secret://db/main#password")
+	require.True(t, ok)
+	assert.Equal(t, config.SecretRef{Path: "db/main", Field: "password"}, ref)
+	assert.Equal(t, "secret://db/main#password", ref.String())
+
+	ref, ok = config.ParseSecretRef("secret://api/token")
+	require.True(t, ok)
+	assert.Equal(t, "", ref.Field)
+
+	_, ok = config.ParseSecretRef("plain")
+	assert.False(t, ok)
+	_, ok = config.ParseSecretRef("secret://")
+	assert.False(t, ok)
+}
+
+func TestSecretManager(t *testing.T) {
+	secretsPath := filepath.Join(t.TempDir(), "secrets.json")
+	writeSecrets(t, secretsPath, `{"db/main": {"password": "s3cr3t", "port": 5432}, "api/token": "abc"}`)
+
+	t.Run("Builder resolves references", func(t *testing.T) {
+		base := config.NewConfig()
+		base.SetData(map[string]interface{}{
+			"db": map[string]interface{}{
+				"host":     "localhost",
+				"password": "secret://db/main#password",
+			},
+			"tokens": []interface{}{"secret://api/token"},
+		})
+
+		manager := config.NewSecretManager(config.NewFileSecretResolver(secretsPath))
+		cfg, err := config.NewConfigBuilder().WithConfig(base).WithSecrets(manager).Build()
+		require.NoError(t, err)
+
+		password, err := cfg.GetString("db.password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", password)
+
+		tokens, err := cfg.GetValue("tokens")
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"abc"}, tokens)
+
+		port, err := manager.Resolve(config.SecretRef{Path: "db/main", Field: "port"})
+		require.NoError(t, err)
+		assert.Equal(t, "5432", port)
+	})
+
+	t.Run("Unresolvable references are reported per key", func(t *testing.T) {
+		base := config.NewConfig()
+		base.SetData(map[string]interface{}{
+			"a": "secret://missing#x",
+			"b": "secret://db/main#missing",
+		})
+
+		manager := config.NewSecretManager(config.NewFileSecretResolver(secretsPath))
+		err := manager.Process(base.Data)
+		require.Error(t, err)
+
+		multi, ok := err.(config.MultiError)
+		require.True(t, ok)
+		assert.Len(t, multi.Errors, 2)
+
+		var secretErr *config.SecretError
+		require.ErrorAs(t, multi.Errors[0], &secretErr)
+		var keyErr *config.KeyError
+		assert.ErrorAs(t, secretErr, &keyErr)
+
+		_, err = config.NewConfigBuilder().WithConfig(base).WithSecrets(manager).Build()
+		assert.Error(t, err)
+	})
+
+	t.Run("Secrets are cached until TTL expires", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "secrets.json")
+		writeSecrets(t, path, `{"api/token": "v1"}`)
+		manager := config.NewSecretManager(config.NewFileSecretResolver(path).WithTTL(50 * time.Millisecond))
+		ref := config.SecretRef{Path: "api/token"}
+
+		value, err := manager.Resolve(ref)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value)
+
+		writeSecrets(t, path, `{"api/token": "v2"}`)
+		value, err = manager.Resolve(ref)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", value) // Cached
+
+		time.Sleep(60 * time.Millisecond)
+		changed, err := manager.Changed()
+		require.NoError(t, err)
+		assert.True(t, changed)
+
+		value, err = manager.Resolve(ref)
+		require.NoError(t, err)
+		assert.Equal(t, "v2", value)
+	})
+
+	t.Run("Leases are renewed with callbacks", func(t *testing.T) {
+		resolver := &leasedResolver{value: "v1", ttl: 150 * time.Millisecond}
+		manager := config.NewSecretManager(resolver)
+
+		var renewed []config.SecretRef
+		manager.OnLeaseRenew(func(ref config.SecretRef, secret config.Secret) {
+			renewed = append(renewed, ref)
+			assert.Equal(t, "lease-1", secret.LeaseID)
+		})
+
+		ref := config.SecretRef{Path: "db/dynamic", Field: "password"}
+		_, err := manager.Resolve(ref)
+		require.NoError(t, err)
+
+		changed, err := manager.Changed()
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, 0, resolver.renews, "Not due before 2/3 of the TTL")
+
+		time.Sleep(110 * time.Millisecond)
+		changed, err = manager.Changed()
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, 1, resolver.renews, "Renewed while the lease is alive")
+		assert.Equal(t, 1, resolver.resolves)
+		assert.Equal(t, []config.SecretRef{ref}, renewed)
+
+		resolver.failRenew = true
+		time.Sleep(160 * time.Millisecond)
+		_, err = manager.Resolve(ref)
+		require.NoError(t, err)
+		assert.Equal(t, 2, resolver.resolves) // Fell back to a fresh resolve
+	})
+
+	t.Run("Unreferenced secrets are dropped", func(t *testing.T) {
+		resolver := &leasedResolver{value: "v1", ttl: 30 * time.Millisecond}
+		manager := config.NewSecretManager(resolver)
+
+		require.NoError(t, manager.Process(map[string]interface{}{"password": "secret://db/dynamic"}))
+		require.NoError(t, manager.Process(map[string]interface{}{"password": "plain"}))
+		assert.Equal(t, 1, resolver.resolves)
+
+		time.Sleep(40 * time.Millisecond)
+		changed, err := manager.Changed()
+		require.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, 1, resolver.resolves)
+		assert.Equal(t, 0, resolver.renews)
+	})
+
+	t.Run("Watcher re-resolves on reload", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "secrets.json")
+		writeSecrets(t, path, `{"api/token": "v1"}`)
+		filePath := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"token": "secret://api/token"}`), 0644))
+
+		manager := config.NewSecretManager(config.NewFileSecretResolver(path).WithTTL(20 * time.Millisecond))
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).WithSecrets(manager).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, 30*time.Millisecond, filePath)
+		require.NoError(t, err)
+		watcher.AddProcessor(manager)
+
+		reloadCh := make(chan string, 1)
+		watcher.OnReload(func(c *config.Config) {
+			token, _ := c.GetString("token")
+			if token == "v2" {
+				select {
+				case reloadCh <- token:
+				default:
+				}
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		writeSecrets(t, path, `{"api/token": "v2"}`)
+
+		select {
+		case token := <-reloadCh:
+			assert.Equal(t, "v2", token)
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting secret refresh")
+		}
+	})
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitiveKeys(t *testing.T) {
+	newSensitiveConfig := func() *config.Config {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db": map[string]interface{}{
+				"host":     "localhost",
+				"Password": "s3cr3t",
+				"credentials": map[string]interface{}{
+					"user": "admin",
+				},
+			},
+			"api": map[string]interface{}{
+				"access_token": "abc",
+				"endpoint":     "https://api",
+			},
+			"tls": map[string]interface{}{
+				"cert": "CERT",
+			},
+			"servers": []interface{}{
+				map[string]interface{}{"host": "a", "secret": "x"},
+			},
+		})
+		return cfg
+	}
+
+	t.Run("Default patterns", func(t *testing.T) {
+		cfg := newSensitiveConfig()
+		assert.True(t, cfg.IsSensitive("db.password"))
+		assert.True(t, cfg.IsSensitive("db.Password"))
+		assert.True(t, cfg.IsSensitive("api.access_token"))
+		assert.True(t, cfg.IsSensitive("db.credentials"))
+		assert.False(t, cfg.IsSensitive("db.host"))
+		assert.False(t, cfg.IsSensitive("tls.cert"))
+	})
+
+	t.Run("Custom patterns", func(t *testing.T) {
+		cfg := newSensitiveConfig()
+		cfg.MarkSensitive("tls.*", "*endpoint")
+		assert.True(t, cfg.IsSensitive("tls.cert"))
+		assert.True(t, cfg.IsSensitive("api.endpoint"))
+		assert.False(t, cfg.IsSensitive("db.host"))
+	})
+
+	t.Run("Dumps are redacted", func(t *testing.T) {
+		cfg := newSensitiveConfig()
+		cfg.MarkSensitive("tls.cert")
+
+		redacted := cfg.Redacted()
+		db := redacted["db"].(map[string]interface{})
+		assert.Equal(t, config.RedactedValue, db["Password"])
+		assert.Equal(t, config.RedactedValue, db["credentials"])
+		assert.Equal(t, "localhost", db["host"])
+		assert.Equal(t, config.RedactedValue, redacted["tls"].(map[string]interface{})["cert"])
+
+		server := redacted["servers"].([]interface{})[0].(map[string]interface{})
+		assert.Equal(t, config.RedactedValue, server["secret"])
+		assert.Equal(t, "a", server["host"])
+
+		dump, err := cfg.Dump()
+		require.NoError(t, err)
+		assert.NotContains(t, string(dump), "s3cr3t")
+		assert.NotContains(t, string(dump), "CERT")
+
+		var decoded map[string]interface{}
+		require.NoError(t, json.Unmarshal(dump, &decoded))
+
+		for _, output := range []string{cfg.String(), fmt.Sprintf("%v", cfg), fmt.Sprint(config.NewConfigWithCache(cfg))} {
+			assert.NotContains(t, output, "s3cr3t")
+			assert.NotContains(t, output, "abc")
+			assert.Contains(t, output, "localhost")
+		}
+	})
+
+	t.Run("Structured logs are redacted", func(t *testing.T) {
+		cfg := newSensitiveConfig()
+		cached := config.NewConfigWithCache(cfg)
+
+		var buf bytes.Buffer
+		for _, handler := range []slog.Handler{slog.NewJSONHandler(&buf, nil), slog.NewTextHandler(&buf, nil)} {
+			buf.Reset()
+			logger := slog.New(handler)
+			logger.Info("loaded", "config", cfg)
+			logger.Info("loaded", "config", cached)
+
+			assert.NotContains(t, buf.String(), "s3cr3t")
+			assert.NotContains(t, buf.String(), "abc")
+			assert.Contains(t, buf.String(), "localhost")
+			assert.Contains(t, buf.String(), config.RedactedValue)
+		}
+	})
+
+	t.Run("Getters return real values", func(t *testing.T) {
+		cfg := newSensitiveConfig()
+		password, err := cfg.GetString("db.Password")
+		require.NoError(t, err)
+		assert.Equal(t, "s3cr3t", password)
+
+		// Data is not modified by redaction
+		_ = cfg.String()
+		assert.Equal(t, "s3cr3t", cfg.Data["db"].(map[string]interface{})["Password"])
+	})
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrictMode(t *testing.T) {
+	dir := t.TempDir()
+	overridePath := filepath.Join(dir, "override.json")
+	require.NoError(t, os.WriteFile(overridePath, []byte(`{"db": {"hots": "db.internal"}, "extra": {"a": 1}}`), 0644))
+
+	schema := config.NewSchema()
+	schema.String("db.host").Required()
+	schema.Int("db.port")
+	schema.Bool("db.ssl")
+	schema.String("app.name")
+	schema.String("app.version")
+	schema.Map("app.labels")
+
+	t.Run("Unknown keys with source and suggestion", func(t *testing.T) {
+		_, err := config.NewConfigBuilder().
+			WithJSON(getTestFilePath(t, "base_config.json")).
+			WithJSON(overridePath).
+			WithStrict(config.NewStrictChecker(config.StrictError).WithSchema(schema)).
+			Build()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "unknown key 'db.hots' (from "+overridePath+"), did you mean 'db.host'?")
+		assert.Contains(t, err.Error(), "unknown key 'extra' (from "+overridePath+")")
+		assert.NotContains(t, err.Error(), "'extra' (from "+overridePath+"), did you mean")
+	})
+
+	t.Run("Warning level calls the hook", func(t *testing.T) {
+		var warnings []*config.UnknownKeyError
+		strict := config.NewStrictChecker(config.StrictWarn).
+			WithSchema(schema).
+			OnWarning(func(err *config.UnknownKeyError) { warnings = append(warnings, err) })
+
+		cfg, err := config.NewConfigBuilder().
+			WithJSON(getTestFilePath(t, "base_config.json")).
+			WithJSON(overridePath).
+			WithStrict(strict).
+			Build()
+		require.NoError(t, err)
+		require.NotNil(t, cfg)
+
+		require.Len(t, warnings, 2)
+		assert.Equal(t, "db.hots", warnings[0].Key)
+		assert.Equal(t, "db.host", warnings[0].Suggestion)
+		assert.Equal(t, overridePath, warnings[0].Source)
+		assert.Equal(t, "extra", warnings[1].Key)
+	})
+
+	t.Run("Open maps accept nested keys", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"app": map[string]interface{}{
+				"name":   "x",
+				"labels": map[string]interface{}{"team": "core"},
+			},
+		})
+		strict := config.NewStrictChecker(config.StrictError).WithSchema(schema)
+		assert.NoError(t, strict.ValidateConfig(cfg))
+		assert.Empty(t, strict.UnknownKeys(cfg))
+	})
+
+	t.Run("Keys declared by a struct", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db":     map[string]interface{}{"host": "x", "prot": 1.0, "ssl": true},
+			"name":   "api",
+			"labels": map[string]interface{}{"any": "thing"},
+			"retrys": 3.0,
+		})
+
+		strict := config.NewStrictChecker(config.StrictError).WithStruct(&serviceSettings{})
+		unknown := strict.UnknownKeys(cfg)
+		require.Len(t, unknown, 2)
+		assert.Equal(t, "db.prot", unknown[0].Key)
+		assert.Equal(t, "db.port", unknown[0].Suggestion)
+		assert.Equal(t, "retrys", unknown[1].Key)
+		assert.Equal(t, "retries", unknown[1].Suggestion)
+	})
+
+	t.Run("Self-referencing structs", func(t *testing.T) {
+		type node struct {
+			Name  string `config:"name"`
+			Child *node  `config:"child"`
+		}
+		strict := config.NewStrictChecker(config.StrictError).WithStruct(&node{})
+
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"name":  "root",
+			"child": map[string]interface{}{"name": "leaf", "child": map[string]interface{}{}},
+			"nmae":  "typo",
+		})
+		unknown := strict.UnknownKeys(cfg)
+		require.Len(t, unknown, 1)
+		assert.Equal(t, "nmae", unknown[0].Key)
+	})
+
+	t.Run("Explicit keys and env origins", func(t *testing.T) {
+		t.Setenv("STRICTTEST_DB_HOTS", "x")
+
+		cfg := config.NewConfig()
+		require.NoError(t, cfg.LoadFromEnv("STRICTTEST_"))
+
+		strict := config.NewStrictChecker(config.StrictError).WithKeys("db.host").WithOpenKeys("features")
+		err := strict.ValidateConfig(cfg)
+		require.Error(t, err)
+		assert.Equal(t, "unknown key 'db.hots' (from env STRICTTEST_DB_HOTS), did you mean 'db.host'?",
+			err.(config.MultiError).Errors[0].Error())
+	})
+}
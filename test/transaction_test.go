@@ -0,0 +1,101 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingParticipant log the phases it goes through
+type recordingParticipant struct {
+	name  string
+	veto  bool
+	calls *[]string
+}
+
+func (p *recordingParticipant) Prepare(next *config.Config) error {
+	*p.calls = append(*p.calls, p.name+".prepare")
+	if p.veto {
+		return errors.New(p.name + " cannot apply")
+	}
+	return nil
+}
+
+func (p *recordingParticipant) Commit(next *config.Config) {
+	*p.calls = append(*p.calls, p.name+".commit")
+}
+
+func (p *recordingParticipant) Rollback(next *config.Config) {
+	*p.calls = append(*p.calls, p.name+".rollback")
+}
+
+func TestTransactionalReload(t *testing.T) {
+	setup := func(t *testing.T) (string, *config.Config, *config.ConfigWatcher) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"pool": {"size": 5}}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		t.Cleanup(watcher.Stop)
+		return filePath, cfg, watcher
+	}
+
+	t.Run("Commit after every participant prepared", func(t *testing.T) {
+		filePath, cfg, watcher := setup(t)
+
+		var calls []string
+		watcher.AddParticipant(&recordingParticipant{name: "pool", calls: &calls})
+		watcher.AddParticipant(config.ReloadFuncs{
+			PrepareFunc: func(next *config.Config) error {
+				size, _ := next.GetInt("pool.size")
+				live, _ := cfg.GetInt("pool.size")
+				calls = append(calls, "tls.prepare")
+				assert.Equal(t, 10, size, "Prepare sees the candidate")
+				assert.Equal(t, 5, live, "The old config is live during prepare")
+				return nil
+			},
+			CommitFunc: func(next *config.Config) { calls = append(calls, "tls.commit") },
+		})
+		watcher.OnReload(func(c *config.Config) { calls = append(calls, "reload") })
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"pool": {"size": 10}}`), 0644))
+		require.NoError(t, watcher.Reload())
+
+		assert.Equal(t, []string{"pool.prepare", "tls.prepare", "pool.commit", "tls.commit", "reload"}, calls)
+		size, _ := cfg.GetInt("pool.size")
+		assert.Equal(t, 10, size)
+	})
+
+	t.Run("A veto rolls back and keeps the old config", func(t *testing.T) {
+		filePath, cfg, watcher := setup(t)
+
+		var calls []string
+		watcher.AddParticipant(&recordingParticipant{name: "a", calls: &calls})
+		watcher.AddParticipant(&recordingParticipant{name: "b", calls: &calls})
+		watcher.AddParticipant(&recordingParticipant{name: "c", veto: true, calls: &calls})
+		watcher.AddParticipant(&recordingParticipant{name: "d", calls: &calls})
+
+		reloaded := false
+		watcher.OnReload(func(c *config.Config) { reloaded = true })
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"pool": {"size": 10}}`), 0644))
+		err := watcher.Reload()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reload vetoed: c cannot apply")
+		assert.Equal(t, err, watcher.LastError())
+
+		assert.Equal(t, []string{"a.prepare", "b.prepare", "c.prepare", "b.rollback", "a.rollback"}, calls)
+		assert.False(t, reloaded)
+		size, _ := cfg.GetInt("pool.size")
+		assert.Equal(t, 5, size)
+	})
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReloadTriggers(t *testing.T) {
+	setup := func(t *testing.T) (string, *config.Config, *config.ConfigWatcher) {
+		filePath := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "info"}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now().Add(time.Hour) // Ignore mtime polling
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		watcher.SetDebounce(time.Hour) // Only explicit triggers reload
+		return filePath, cfg, watcher
+	}
+
+	t.Run("Reload applies changes synchronously", func(t *testing.T) {
+		filePath, cfg, watcher := setup(t)
+		defer watcher.Stop()
+
+		reloaded := 0
+		watcher.OnReload(func(c *config.Config) { reloaded++ })
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "debug"}`), 0644))
+		require.NoError(t, watcher.Reload())
+		level, _ := cfg.GetString("level")
+		assert.Equal(t, "debug", level)
+		assert.Equal(t, 1, reloaded)
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": `), 0644))
+		assert.Error(t, watcher.Reload())
+		assert.Error(t, watcher.LastError())
+		level, _ = cfg.GetString("level")
+		assert.Equal(t, "debug", level)
+	})
+
+	t.Run("Trigger channel", func(t *testing.T) {
+		filePath, cfg, watcher := setup(t)
+		go watcher.Start()
+		defer watcher.Stop()
+
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "warn"}`), 0644))
+		watcher.Trigger() <- struct{}{}
+
+		assert.Eventually(t, func() bool {
+			level, _ := cfg.GetString("level")
+			return level == "warn"
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("SIGHUP", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("SIGHUP is not supported on windows")
+		}
+
+		filePath, cfg, watcher := setup(t)
+		watcher.ReloadOnSignal()
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond) // Wait for the signal handler
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "error"}`), 0644))
+
+		process, err := os.FindProcess(os.Getpid())
+		require.NoError(t, err)
+		require.NoError(t, process.Signal(syscall.SIGHUP))
+
+		assert.Eventually(t, func() bool {
+			level, _ := cfg.GetString("level")
+			return level == "error"
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DarioChiappello/gump/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type dbSettings struct {
+	Host     string        `config:"host" validate:"required,hostname"`
+	Port     int           `config:"port" validate:"required,min=1,max=65535"`
+	SSL      bool          `json:"ssl"`
+	Timeout  time.Duration `config:"timeout"`
+	Password *string       `config:"password"`
+}
+
+type serviceSettings struct {
+	DB      dbSettings        `config:"db"`
+	Name    string            `config:"name" validate:"required,min=1,max=16"`
+	Level   string            `config:"level" validate:"oneof=debug info warn"`
+	Tags    []string          `config:"tags" validate:"max=3"`
+	Labels  map[string]string `config:"labels"`
+	Ratio   float64           `config:"ratio"`
+	Retries uint8
+	Ignored string `config:"-"`
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Run("Bind nested values", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db": map[string]interface{}{
+				"host":     "db.internal",
+				"port":     5432.0,
+				"ssl":      "true",
+				"timeout":  "5s",
+				"password": "s3cr3t",
+			},
+			"name":    "api",
+			"level":   "info",
+			"tags":    "a, b",
+			"labels":  map[string]interface{}{"team": "core"},
+			"ratio":   "0.5",
+			"retries": 3.0,
+			"Ignored": "x",
+		})
+
+		var settings serviceSettings
+		require.NoError(t, cfg.Unmarshal(&settings))
+
+		assert.Equal(t, "db.internal", settings.DB.Host)
+		assert.Equal(t, 5432, settings.DB.Port)
+		assert.True(t, settings.DB.SSL)
+		assert.Equal(t, 5*time.Second, settings.DB.Timeout)
+		require.NotNil(t, settings.DB.Password)
+		assert.Equal(t, "s3cr3t", *settings.DB.Password)
+		assert.Equal(t, []string{"a", "b"}, settings.Tags)
+		assert.Equal(t, map[string]string{"team": "core"}, settings.Labels)
+		assert.Equal(t, 0.5, settings.Ratio)
+		assert.Equal(t, uint8(3), settings.Retries)
+		assert.Empty(t, settings.Ignored)
+	})
+
+	t.Run("UnmarshalKey binds a subtree", func(t *testing.T) {
+		cfg := config.NewConfig()
+		require.NoError(t, cfg.LoadFromJSON(getTestFilePath(t, "base_config.json")))
+
+		var db dbSettings
+		require.NoError(t, cfg.UnmarshalKey("db", &db))
+		assert.Equal(t, "localhost", db.Host)
+		assert.Equal(t, 5432, db.Port)
+
+		assert.Error(t, cfg.UnmarshalKey("missing", &db))
+		assert.Error(t, cfg.Unmarshal(db))
+	})
+
+	t.Run("Errors name config keys", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{
+			"db": map[string]interface{}{
+				"host": "bad host!",
+				"port": 70000.0,
+				"ssl":  "maybe",
+			},
+			"name":  "",
+			"level": "trace",
+			"tags":  []interface{}{"a", "b", "c", "d"},
+		})
+
+		var settings serviceSettings
+		err := cfg.Unmarshal(&settings)
+		require.Error(t, err)
+
+		var keys []string
+		for _, e := range err.(config.MultiError).Errors {
+			var typeErr *config.TypeError
+			var validationErr *config.ValidationError
+			switch {
+			case errors.As(e, &typeErr):
+				keys = append(keys, typeErr.Key)
+			case errors.As(e, &validationErr):
+				keys = append(keys, validationErr.Key)
+			default:
+				t.Fatalf("unexpected error type %T", e)
+			}
+		}
+		assert.Equal(t, []string{"db.host", "db.port", "db.ssl", "name", "level", "tags"}, keys)
+	})
+
+	t.Run("Interface fields the value does not implement", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"reader": "not a reader", "any": "x"})
+
+		var settings struct {
+			Reader io.Reader   `config:"reader"`
+			Any    interface{} `config:"any"`
+		}
+		var err error
+		assert.NotPanics(t, func() { err = cfg.Unmarshal(&settings) })
+		var typeErr *config.TypeError
+		require.True(t, errors.As(err, &typeErr))
+		assert.Equal(t, "reader", typeErr.Key)
+		assert.Equal(t, "io.Reader", typeErr.Expected)
+
+		assert.NotPanics(t, func() {
+			_, err = config.Get[io.Reader](cfg, "reader")
+		})
+		assert.Error(t, err)
+		assert.NotPanics(t, func() {
+			reader := config.Dynamic[io.Reader](cfg, "reader", nil)
+			assert.Nil(t, reader.Load())
+		})
+	})
+
+	t.Run("Missing required keys", func(t *testing.T) {
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"db": map[string]interface{}{}})
+
+		var settings serviceSettings
+		err := cfg.Unmarshal(&settings)
+		require.Error(t, err)
+
+		var keys []string
+		for _, e := range err.(config.MultiError).Errors {
+			keyErr, ok := e.(*config.KeyError)
+			require.True(t, ok, "Must be a KeyError")
+			keys = append(keys, keyErr.Key)
+		}
+		assert.Equal(t, []string{"db.host", "db.port", "name"}, keys)
+	})
+
+	t.Run("Custom rules", func(t *testing.T) {
+		config.RegisterValidationRule("prefix", func(value interface{}, param string) error {
+			if str, ok := value.(string); ok && strings.HasPrefix(str, param) {
+				return nil
+			}
+			return fmt.Errorf("must start with %q", param)
+		})
+
+		type bucket struct {
+			Name string `config:"name" validate:"prefix=prod-"`
+			Size int    `config:"size" validate:"unknownrule"`
+		}
+
+		cfg := config.NewConfig()
+		cfg.SetData(map[string]interface{}{"name": "dev-logs", "size": 1.0})
+
+		var b bucket
+		err := cfg.Unmarshal(&b)
+		require.Error(t, err)
+		multi := err.(config.MultiError)
+		require.Len(t, multi.Errors, 2)
+		assert.Equal(t, `invalid value for key 'name': must start with "prod-"`, multi.Errors[0].Error())
+		assert.Contains(t, multi.Errors[1].Error(), "unknown validation rule")
+
+		cfg.SetData(map[string]interface{}{"name": "prod-logs"})
+		assert.NoError(t, cfg.Unmarshal(&struct {
+			Name string `config:"name" validate:"prefix=prod-"`
+		}{}))
+	})
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sync"
@@ -120,4 +121,196 @@ func TestConfigWatcher(t *testing.T) {
 		}
 	})
 
+	t.Run("Reload rebuilds the builder recipe", func(t *testing.T) {
+		basePath := createConfigFile("recipe_base.json", `{"db": {"host": "base", "port": 1}, "app": {"name": "GUMP"}}`)
+		overridePath := createConfigFile("recipe_override.json", `{"db": {"host": "override", "pool": 5}}`)
+		t.Setenv("RECIPETEST_LEVEL", "debug")
+
+		cfg, err := config.NewConfigBuilder().
+			WithJSON(basePath).
+			WithJSON(overridePath).
+			WithEnv("RECIPETEST_").
+			WithOverrides([]string{"db.port=2"}).
+			Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		// Files and sources of the recipe are watched without listing them
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+
+		reloadCh := make(chan bool, 1)
+		watcher.OnReload(func(c *config.Config) {
+			select {
+			case reloadCh <- true:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(overridePath, []byte(`{"db": {"host": "override2"}}`), 0644))
+
+		select {
+		case <-reloadCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting recipe reload")
+		}
+
+		host, err := cfg.GetString("db.host")
+		require.NoError(t, err)
+		assert.Equal(t, "override2", host)
+
+		_, err = cfg.GetValue("db.pool")
+		assert.Error(t, err, "Keys removed from a file must disappear")
+
+		port, _ := cfg.GetInt("db.port")
+		assert.Equal(t, 2, port, "Later layers keep their precedence")
+		level, _ := cfg.GetString("level")
+		assert.Equal(t, "debug", level)
+		name, _ := cfg.GetString("app.name")
+		assert.Equal(t, "GUMP", name)
+	})
+
+	t.Run("Layers chained after Build do not leak into the recipe", func(t *testing.T) {
+		path := createConfigFile("recipe_snapshot.json", `{"level": "info"}`)
+
+		builder := config.NewConfigBuilder().WithJSON(path)
+		cfg, err := builder.Build()
+		require.NoError(t, err)
+		builder.WithOverrides([]string{"extra=1"})
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		defer watcher.Stop()
+		require.NoError(t, watcher.Reload())
+
+		_, err = cfg.GetValue("extra")
+		assert.Error(t, err)
+	})
+
+	t.Run("Reload keeps keys not loaded from watched files", func(t *testing.T) {
+		filePath := createConfigFile("base_layer.json", `{"app": {"name": "GUMP", "debug": true}}`)
+		cfg := config.NewConfig()
+		require.NoError(t, cfg.LoadFromJSON(filePath))
+		cfg.Set("runtime.id", "abc")
+		cfg.LastModified = time.Now()
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour, filePath)
+		require.NoError(t, err)
+
+		reloadCh := make(chan bool, 1)
+		watcher.OnReload(func(c *config.Config) {
+			select {
+			case reloadCh <- true:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"app": {"name": "GUMP2"}}`), 0644))
+
+		select {
+		case <-reloadCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting reload")
+		}
+
+		name, _ := cfg.GetString("app.name")
+		assert.Equal(t, "GUMP2", name)
+		_, err = cfg.GetValue("app.debug")
+		assert.Error(t, err)
+		id, err := cfg.GetString("runtime.id")
+		require.NoError(t, err)
+		assert.Equal(t, "abc", id)
+	})
+	t.Run("Atomic saves reload once per burst", func(t *testing.T) {
+		dir := t.TempDir()
+		filePath := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"version": 0}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		watcher, err := config.NewConfigWatcher(cfg, time.Hour)
+		require.NoError(t, err)
+		watcher.SetDebounce(150 * time.Millisecond)
+
+		var mu sync.Mutex
+		reloads := 0
+		watcher.OnReload(func(c *config.Config) {
+			mu.Lock()
+			reloads++
+			mu.Unlock()
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		for i := 1; i <= 3; i++ {
+			// Write-temp-then-rename, like editors and config management tools
+			tmp := filepath.Join(dir, ".config.json.tmp")
+			require.NoError(t, os.WriteFile(tmp, []byte(fmt.Sprintf(`{"version": %d}`, i)), 0644))
+			require.NoError(t, os.Rename(tmp, filePath))
+			time.Sleep(20 * time.Millisecond)
+		}
+
+		assert.Eventually(t, func() bool {
+			version, _ := cfg.GetInt("version")
+			return version == 3
+		}, 2*time.Second, 20*time.Millisecond)
+		time.Sleep(300 * time.Millisecond)
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.Equal(t, 1, reloads)
+	})
+
+	t.Run("Removed files and folders are watched again", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "conf")
+		require.NoError(t, os.Mkdir(dir, 0755))
+		filePath := filepath.Join(dir, "config.json")
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "info"}`), 0644))
+
+		cfg, err := config.NewConfigBuilder().WithJSON(filePath).Build()
+		require.NoError(t, err)
+		cfg.LastModified = time.Now()
+
+		watcher, err := config.NewConfigWatcher(cfg, 50*time.Millisecond)
+		require.NoError(t, err)
+		watcher.SetDebounce(20 * time.Millisecond)
+
+		errCh := make(chan error, 8)
+		watcher.OnReloadError(func(err error) {
+			select {
+			case errCh <- err:
+			default:
+			}
+		})
+		go watcher.Start()
+		defer watcher.Stop()
+
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, os.RemoveAll(dir))
+
+		select {
+		case <-errCh:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Timeout waiting removal")
+		}
+		level, _ := cfg.GetString("level")
+		assert.Equal(t, "info", level, "Last good config stays active")
+
+		require.NoError(t, os.Mkdir(dir, 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(`{"level": "debug"}`), 0644))
+
+		assert.Eventually(t, func() bool {
+			level, _ := cfg.GetString("level")
+			return level == "debug"
+		}, 2*time.Second, 20*time.Millisecond)
+	})
 }